@@ -0,0 +1,85 @@
+package ast
+
+import "sort"
+
+// CodeInfo documents an error code.
+type CodeInfo struct {
+	Code  uint   `json:"code"`
+	Title string `json:"title"`
+	Doc   string `json:"doc"`
+}
+
+// Codes is the catalog of all error codes sorted by code. Codes are grouped by hundreds:
+//
+//	1xx lexer and token errors
+//	2xx ast structure errors
+//	3xx type parsing errors
+//	4xx literal parsing errors
+//	5xx expression resolution and evaluation errors
+//	6xx user errors
+//	7xx lint warnings
+var Codes = []CodeInfo{
+	{100, "read failed", "The input could not be read. The cause holds the reader error."},
+	{101, "unexpected token start", "The input contains a character that cannot start a token, " +
+		"like a backslash or a non-ascii character outside of strings."},
+	{102, "adjacent zeros", "A number starts with a zero that is directly followed by a digit. " +
+		"Leading zeros are not allowed, use a single 0 or a fraction like 0.5."},
+	{103, "expect number fraction", "A number with a decimal point must have at least one digit " +
+		"after the point."},
+	{104, "expect number exponent", "A number with an exponent must have at least one digit " +
+		"after the 'e' and optional sign."},
+	{105, "unterminated string", "A string is missing its closing quote. Single and double quoted " +
+		"strings must end on the same line, use backtick quotes for multiline strings."},
+	{106, "invalid string quoting", "A string contains an invalid escape sequence."},
+	{111, "unterminated tree", "An opening parenthesis, bracket, brace or angle bracket is " +
+		"missing its closing counterpart."},
+	{112, "invalid separator", "A comma is used where no element precedes it. Commas are " +
+		"optional and may only follow an element."},
+	{113, "invalid tag", "A tag is missing its key or the key is not a symbol, string or number."},
+	{201, "unexpected input", "The ast is not valid in this position."},
+	{202, "expect sym", "A symbol was expected, for example as name in a declaration."},
+	{203, "expect tag", "A tag was expected, for example as field in a keyr or declaration."},
+	{301, "invalid type", "The type name or type syntax is not known."},
+	{302, "invalid type parameters", "The type parameters or fields are malformed."},
+	{400, "expect kind", "A literal of a specific kind was expected, like a list or keyr."},
+	{401, "invalid bool", "A bool literal must be true or false."},
+	{402, "invalid literal", "A literal could not be parsed or converted to the expected type. " +
+		"The cause holds the conversion error."},
+	{403, "invalid manifest", "A plugin manifest is missing its name or has a malformed capability. " +
+		"The name holds the invalid field."},
+	{501, "unexpected exp", "The expression kind is not supported in this position."},
+	{510, "sym unresolved", "A symbol could not be resolved in the environment. Check the name " +
+		"and the imported modules."},
+	{511, "typ unresolved", "A type could not be resolved, for example a type reference to an " +
+		"unknown schema or module declaration."},
+	{512, "spec resolution failed", "A spec call could not be resolved. The name is the spec and " +
+		"the cause holds the reason."},
+	{520, "unify failed", "Two types are incompatible, for example an argument type does not " +
+		"match the parameter type."},
+	{521, "layout failed", "The call arguments do not match the spec signature, for example " +
+		"missing, surplus or unknown tagged arguments."},
+	{530, "eval failed", "A call failed during evaluation. The cause holds the error returned " +
+		"by the spec."},
+	{600, "user error", "An error raised explicitly by a program."},
+	{601, "assertion failed", "An assert or expect spec failed. The cause holds the got and " +
+		"wanted values and the delta between them."},
+	{701, "unused binding", "A name bound by a with expression is never referenced. Remove the " +
+		"binding or use its value."},
+	{702, "shadowed name", "A with binding or func parameter hides a name of the same kind " +
+		"bound by an enclosing expression."},
+	{703, "unreachable case", "A switch case is equal to a preceding case and can never match."},
+	{704, "constant condition", "An if condition is a literal and always takes or skips the " +
+		"same branch."},
+	{705, "deprecated spec", "A spec marked as deprecated in its documentation is called. The " +
+		"help holds the notice, that usually names a replacement."},
+	{706, "unused import", "An imported module is never referenced by a symbol or type."},
+}
+
+// LookupCode returns the catalog entry for code or nil.
+func LookupCode(code uint) *CodeInfo {
+	i := sort.Search(len(Codes), func(i int) bool { return Codes[i].Code >= code })
+	if i < len(Codes) && Codes[i].Code == code {
+		return &Codes[i]
+	}
+	return nil
+}
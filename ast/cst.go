@@ -0,0 +1,230 @@
+package ast
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/knd"
+)
+
+// Node is a lossless concrete syntax tree node. It keeps the exact source text of its token and
+// the trivia before it, so that printing an unmodified tree returns the input bytes.
+type Node struct {
+	Tok
+	// Lead holds the whitespace and comma separators before the token.
+	// For tags it is the trivia between the key and the tag rune.
+	Lead string
+	// Text is the exact source text of the token.
+	Text string
+	// Seq holds the tree elements or the key and optional value of tags.
+	Seq []*Node
+	// Close is the closing token of trees. Its lead holds the trivia before the closing rune.
+	Close *Node
+}
+
+// File is the concrete syntax tree of a xelf source with all nodes and the trailing trivia.
+type File struct {
+	Nodes []*Node
+	// Tail holds the trivia after the last node.
+	Tail string
+}
+
+// ReadFile reads and returns the concrete syntax tree of the named reader r or an error.
+// Xelf has no comment syntax, so whitespace and separators are the only trivia.
+func ReadFile(r io.Reader, name string) (*File, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	br := bytes.NewReader(src)
+	s := &cstScanner{l: NewLexer(br, name), r: br, src: src}
+	f := &File{}
+	for {
+		n, err := s.tok()
+		if err == io.EOF {
+			f.Tail = n.Lead
+			return f, nil
+		}
+		if err != nil {
+			return f, err
+		}
+		if n, err = s.rest(n); err != nil {
+			return f, err
+		}
+		f.Nodes = append(f.Nodes, n)
+	}
+}
+
+// NewNode returns a node for the token t with source text text and lead trivia.
+func NewNode(lead string, t Tok, text string) *Node { return &Node{Tok: t, Lead: lead, Text: text} }
+
+// SetRaw sets the token raw value and source text to raw.
+func (n *Node) SetRaw(raw string) {
+	n.Raw = raw
+	n.Text = raw
+}
+
+// Ast returns the node as abstract syntax tree.
+func (n *Node) Ast() Ast {
+	res := Ast{Tok: n.Tok}
+	if len(n.Seq) > 0 {
+		res.Seq = make([]Ast, 0, len(n.Seq))
+		for _, el := range n.Seq {
+			res.Seq = append(res.Seq, el.Ast())
+		}
+	}
+	return res
+}
+
+// Asts returns all nodes of the file as abstract syntax trees.
+func (f *File) Asts() []Ast {
+	res := make([]Ast, 0, len(f.Nodes))
+	for _, n := range f.Nodes {
+		res = append(res, n.Ast())
+	}
+	return res
+}
+
+func (n *Node) String() string {
+	var b strings.Builder
+	n.print(&b)
+	return b.String()
+}
+func (f *File) String() string {
+	var b strings.Builder
+	for _, n := range f.Nodes {
+		n.print(&b)
+	}
+	b.WriteString(f.Tail)
+	return b.String()
+}
+
+// WriteTo writes the source of the file to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, f.String())
+	return int64(n), err
+}
+
+func (n *Node) print(b *strings.Builder) {
+	if n.Kind == knd.Tag && len(n.Seq) > 0 {
+		n.Seq[0].print(b)
+		b.WriteString(n.Lead)
+		b.WriteString(n.Text)
+		for _, el := range n.Seq[1:] {
+			el.print(b)
+		}
+		return
+	}
+	b.WriteString(n.Lead)
+	b.WriteString(n.Text)
+	for _, el := range n.Seq {
+		el.print(b)
+	}
+	if c := n.Close; c != nil {
+		b.WriteString(c.Lead)
+		b.WriteString(c.Text)
+	}
+}
+
+type cstScanner struct {
+	l    *Lexer
+	r    *bytes.Reader
+	src  []byte
+	mark int
+}
+
+// tok returns the next token as node with its source text and leading trivia.
+func (s *cstScanner) tok() (*Node, error) {
+	t, err := s.l.Tok()
+	// the lexer has read one rune ahead of the token
+	end := len(s.src) - s.r.Len() - s.l.nxn
+	if end < s.mark {
+		end = s.mark
+	}
+	text := string(s.src[s.mark:end])
+	s.mark = end
+	i := 0
+	for i < len(text) && cor.Space(rune(text[i])) {
+		i++
+	}
+	return NewNode(text[:i], t, text[i:]), err
+}
+
+func (s *cstScanner) rest(n *Node) (*Node, error) {
+	if n.Kind&(knd.Idxr|knd.Keyr|knd.Typ|knd.Call) == 0 {
+		return n, nil
+	}
+	_, end := parens(n.Kind)
+	if end == 0 {
+		return n, nil
+	}
+	t, err := s.next(n)
+	if err != nil {
+		return n, err
+	}
+	for t.Rune != end {
+		switch t.Rune {
+		case ':', ';':
+			return n, ErrInvalidTag(t.Tok)
+		case ',':
+			return n, ErrInvalidSep(t.Tok)
+		}
+		a, err := s.rest(t)
+		if err != nil {
+			return n, err
+		}
+		if t, err = s.next(n); err != nil {
+			return n, err
+		}
+		if t.Kind == knd.Tag {
+			switch a.Kind {
+			case knd.Sym, knd.Char, knd.Num:
+			default:
+				return n, ErrInvalidTag(t.Tok)
+			}
+			tt := t
+			tt.Src.Pos = a.Src.Pos
+			tt.Seq = []*Node{a}
+			if t, err = s.next(n); err != nil {
+				return n, err
+			}
+			if tt.Rune != ';' && valStart(t.Tok) {
+				b, err := s.rest(t)
+				if err != nil {
+					return n, err
+				}
+				tt.Seq = append(tt.Seq, b)
+				tt.Src.End = b.Src.End
+				if t, err = s.next(n); err != nil {
+					return n, err
+				}
+			}
+			n.Seq = append(n.Seq, tt)
+		} else {
+			n.Seq = append(n.Seq, a)
+		}
+		if t.Rune == ',' {
+			// separators are kept as trivia of the following token
+			c := t
+			if t, err = s.next(n); err != nil {
+				return n, err
+			}
+			t.Lead = c.Lead + c.Text + t.Lead
+		}
+	}
+	n.Close = t
+	n.Src.End = t.Src.End
+	return n, nil
+}
+
+// next returns the next token within tree n and reports the end of input as unterminated tree.
+func (s *cstScanner) next(n *Node) (*Node, error) {
+	t, err := s.tok()
+	if err == io.EOF {
+		return t, ErrTreeTerm(n.Tok)
+	}
+	return t, err
+}
@@ -0,0 +1,77 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	tests := []string{
+		"",
+		"  \n",
+		"1",
+		"(a b)\n",
+		"\n\n(a  b:1 ,c;)\n\n\n[1,2 , 3]\n",
+		"{\n\tname: 'ä ö',\n\n\tnums:[1 2.5E3 -4]\n\tflag;\n}  ",
+		"(fn `multi\nline` <obj id:int name?:str>)\r\n(x)",
+		"{'quoted key' : 1, \"b\":[]}",
+	}
+	for _, raw := range tests {
+		f, err := ReadFile(strings.NewReader(raw), "test")
+		if err != nil {
+			t.Errorf("read %q: %v", raw, err)
+			continue
+		}
+		if got := f.String(); got != raw {
+			t.Errorf("round trip want %q got %q", raw, got)
+		}
+		as, err := ReadAll(strings.NewReader(raw), "test")
+		if err != nil {
+			t.Errorf("read all %q: %v", raw, err)
+			continue
+		}
+		got := f.Asts()
+		for i := range as {
+			fixDoc(&as[i], nil)
+		}
+		for i := range got {
+			fixDoc(&got[i], nil)
+		}
+		if len(as) != len(got) || len(as) > 0 && !reflect.DeepEqual(as, got) {
+			t.Errorf("asts of %q want %v got %v", raw, as, got)
+		}
+	}
+}
+
+func TestReadFileErr(t *testing.T) {
+	tests := []struct {
+		raw string
+		err string
+	}{
+		{"(a b", "test:1:0: unterminated tree E111"},
+		{"{a:0:}", "test:1:4: invalid tag E113"},
+		{"(a ,, b)", "test:1:4: invalid separator E112"},
+	}
+	for _, test := range tests {
+		_, err := ReadFile(strings.NewReader(test.raw), "test")
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("read %q want error %s got %v", test.raw, test.err, err)
+		}
+	}
+}
+
+func TestNodeEdit(t *testing.T) {
+	raw := "{\n\tname:'a'\n\n\tlabel:  'b',\n\tx:1}\n"
+	f, err := ReadFile(strings.NewReader(raw), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyr := f.Nodes[0]
+	keyr.Seq[1].Seq[0].SetRaw("title")
+	keyr.Seq = append(keyr.Seq[:2], keyr.Seq[3:]...)
+	want := "{\n\tname:'a'\n\n\ttitle:  'b'}\n"
+	if got := f.String(); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
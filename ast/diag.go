@@ -0,0 +1,204 @@
+package ast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Diagnostic is the json representation of an error for tooling.
+// Lines are 1-indexed and columns are 0-indexed byte offsets into the line on every line.
+type Diagnostic struct {
+	Code    uint   `json:"code,omitempty"`
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Col     int    `json:"col"`
+	EndLine int    `json:"endLine,omitempty"`
+	EndCol  int    `json:"endCol"`
+	Help    string `json:"help,omitempty"`
+	Cause   string `json:"cause,omitempty"`
+}
+
+// NewDiagnostic returns the diagnostic for err. Errors without a source only have a name.
+func NewDiagnostic(err error) Diagnostic {
+	var e *Error
+	if !errors.As(err, &e) {
+		return Diagnostic{Name: err.Error()}
+	}
+	d := Diagnostic{Code: e.Code, Name: e.Name, Help: e.Help}
+	if e.Src.Doc != nil {
+		d.File = e.Src.Name
+	}
+	if e.Src.Line > 0 {
+		d.Line, d.Col = int(e.Src.Line), col(int(e.Src.Line), int(e.Src.Byte))
+		d.EndLine, d.EndCol = int(e.Src.End.Line), col(int(e.Src.End.Line), int(e.Src.End.Byte))
+	}
+	if c := LookupCode(e.Code); c != nil {
+		d.Title = c.Title
+	}
+	if e.Err != nil {
+		d.Cause = e.Err.Error()
+	}
+	return d
+}
+
+// Diag renders errors as human readable diagnostics with source excerpts or as json.
+type Diag struct {
+	// Source returns the source for a doc name and defaults to reading the file with that name.
+	Source func(name string) ([]byte, error)
+	// Color enables ansi colors for terminal output.
+	Color bool
+
+	srcs map[string][]string
+}
+
+// Render writes all errs with source excerpts to w. The source line of errors with a source
+// position is shown with the error span underlined.
+func (d *Diag) Render(w io.Writer, errs ...error) error {
+	var b strings.Builder
+	for i, err := range errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		d.render(&b, NewDiagnostic(err))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderJSON writes all errs as json list of diagnostics to w.
+func (d *Diag) RenderJSON(w io.Writer, errs ...error) error {
+	res := make([]Diagnostic, 0, len(errs))
+	for _, err := range errs {
+		res = append(res, NewDiagnostic(err))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(res)
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiBlue  = "\x1b[1;34m"
+)
+
+func (d *Diag) paint(b *strings.Builder, style, str string) {
+	if d.Color {
+		b.WriteString(style)
+		b.WriteString(str)
+		b.WriteString(ansiReset)
+	} else {
+		b.WriteString(str)
+	}
+}
+
+func (d *Diag) render(b *strings.Builder, g Diagnostic) {
+	if g.Code != 0 {
+		d.paint(b, ansiRed, fmt.Sprintf("error[E%d]", g.Code))
+	} else {
+		d.paint(b, ansiRed, "error")
+	}
+	d.paint(b, ansiBold, ": "+g.Name)
+	b.WriteByte('\n')
+	if g.Line > 0 {
+		num := fmt.Sprint(g.Line)
+		pad := strings.Repeat(" ", len(num))
+		d.paint(b, ansiBlue, pad+"--> ")
+		fmt.Fprintf(b, "%s:%d:%d\n", g.File, g.Line, srcCol(g.Line, g.Col))
+		if line, ok := d.line(g.File, g.Line); ok {
+			d.paint(b, ansiBlue, pad+" |\n"+num+" | ")
+			b.WriteString(line)
+			b.WriteByte('\n')
+			d.paint(b, ansiBlue, pad+" | ")
+			b.WriteString(indent(line, g.Col))
+			n := 1
+			if g.EndLine == g.Line && g.EndCol > g.Col {
+				n = utf8.RuneCountInString(clip(line, g.Col, g.EndCol))
+			}
+			d.paint(b, ansiRed, strings.Repeat("^", n))
+			b.WriteByte('\n')
+		}
+	}
+	if g.Help != "" {
+		b.WriteString("  = help: ")
+		b.WriteString(g.Help)
+		b.WriteByte('\n')
+	}
+	if g.Cause != "" {
+		b.WriteString("  = cause: ")
+		b.WriteString(strings.ReplaceAll(g.Cause, "\n", "\n\t"))
+		b.WriteByte('\n')
+	}
+}
+
+// line returns the source line with the 1-indexed line number of the named source.
+func (d *Diag) line(name string, n int) (string, bool) {
+	lines, ok := d.srcs[name]
+	if !ok {
+		src := d.Source
+		if src == nil {
+			src = os.ReadFile
+		}
+		if name != "" {
+			if raw, err := src(name); err == nil {
+				lines = strings.Split(string(raw), "\n")
+			}
+		}
+		if d.srcs == nil {
+			d.srcs = make(map[string][]string)
+		}
+		d.srcs[name] = lines
+	}
+	if n < 1 || n > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[n-1], "\r"), true
+}
+
+// col returns the 0-indexed byte column of the source byte c in line n. The lexer counts
+// the bytes of all but the first line from the preceding newline, so we need to subtract one.
+func col(n, c int) int {
+	if n > 1 && c > 0 {
+		return c - 1
+	}
+	return c
+}
+
+// srcCol returns the 0-indexed byte column c in line n as printed by Src.String.
+func srcCol(n, c int) int {
+	if n > 1 {
+		return c + 1
+	}
+	return c
+}
+
+// indent returns whitespace to align with byte column c in line, tabs are kept as is.
+func indent(line string, c int) string {
+	var b strings.Builder
+	for _, r := range clip(line, 0, c) {
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+func clip(s string, start, end int) string {
+	if end > len(s) {
+		end = len(s)
+	}
+	if start > end {
+		start = end
+	}
+	return s[start:end]
+}
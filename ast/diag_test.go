@@ -0,0 +1,137 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiag(t *testing.T) {
+	raw := "(a b)\n\t(c [d e)\n(f 00)"
+	_, errs := ReadAllRecover(strings.NewReader(raw), "test.xelf")
+	if len(errs) != 2 {
+		t.Fatalf("want two errors got %v", errs)
+	}
+	d := &Diag{Source: func(name string) ([]byte, error) {
+		if name != "test.xelf" {
+			return nil, fmt.Errorf("unexpected source %s", name)
+		}
+		return []byte(raw), nil
+	}}
+	var b bytes.Buffer
+	err := d.Render(&b, errs[0], errs[1], fmt.Errorf("other failure"))
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	want := `error[E111]: unterminated tree
+ --> test.xelf:2:5
+  |
+2 | 	(c [d e)
+  | 	   ^
+  = help: expecting closing ']'
+  = cause: EOF
+
+error[E102]: adjacent zeros
+ --> test.xelf:3:4
+  |
+3 | (f 00)
+  |    ^
+  = help: number zero must be followed by a fraction or whitespace
+
+error: other failure
+`
+	if got := b.String(); got != want {
+		t.Errorf("want render:\n%s\ngot:\n%s", want, got)
+	}
+	for _, e := range errs {
+		b.Reset()
+		d.Render(&b, e)
+		head := strings.SplitN(b.String(), "\n", 3)[1]
+		pos := strings.TrimPrefix(head, " --> ")
+		if !strings.HasPrefix(e.Error(), pos+":") {
+			t.Errorf("render position %s does not match error %s", pos, e)
+		}
+	}
+	b.Reset()
+	if err = d.RenderJSON(&b, errs[1]); err != nil {
+		t.Fatalf("render json: %v", err)
+	}
+	var res []Diagnostic
+	if err = json.Unmarshal(b.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	wantd := Diagnostic{Code: 102, Name: "adjacent zeros", Title: "adjacent zeros",
+		File: "test.xelf", Line: 3, Col: 3, EndLine: 3, EndCol: 4,
+		Help: "number zero must be followed by a fraction or whitespace"}
+	if len(res) != 1 || res[0] != wantd {
+		t.Errorf("want json diagnostic %+v got %+v", wantd, res)
+	}
+	d = &Diag{Color: true, Source: d.Source}
+	b.Reset()
+	d.Render(&b, errs[1])
+	if !strings.Contains(b.String(), ansiRed+"^"+ansiReset) {
+		t.Errorf("want colored caret got %q", b.String())
+	}
+}
+
+func TestDiagJSON(t *testing.T) {
+	_, errs := ReadAllRecover(strings.NewReader("(f 00)\n(g 00)"), "test.xelf")
+	if len(errs) != 2 {
+		t.Fatalf("want two errors got %v", errs)
+	}
+	var b bytes.Buffer
+	if err := new(Diag).RenderJSON(&b, errs[0], errs[1]); err != nil {
+		t.Fatalf("render json: %v", err)
+	}
+	var res []Diagnostic
+	if err := json.Unmarshal(b.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("want two diagnostics got %+v", res)
+	}
+	for i, g := range res {
+		if g.Line != i+1 || g.Col != 3 || g.EndLine != i+1 || g.EndCol != 4 {
+			t.Errorf("want diagnostic at line %d col 3 to 4 got %+v", i+1, g)
+		}
+	}
+}
+
+func TestCodes(t *testing.T) {
+	for i, c := range Codes {
+		if i > 0 && Codes[i-1].Code >= c.Code {
+			t.Errorf("codes not sorted at %d", c.Code)
+		}
+		if c.Title == "" || c.Doc == "" {
+			t.Errorf("code %d without title or doc", c.Code)
+		}
+	}
+	var tok Tok
+	var a Ast
+	all := []*Error{ErrTokStart(tok), ErrAdjZero(tok), ErrNumFrac(tok), ErrNumExpo(tok),
+		ErrStrTerm(Tok{Raw: "'"}), ErrUnquote(tok, nil), ErrTreeTerm(tok), ErrInvalidSep(tok),
+		ErrInvalidTag(tok), ErrUnexpected(a), ErrExpectSym(a), ErrExpectTag(a),
+		ErrInvalidType(a.Src, ""), ErrInvalidParams(a), ErrExpect(a, 0), ErrInvalidBool(a),
+		ErrInvalid(a, 0, nil), ErrUnexpectedExp(a.Src, nil), ErrReslSym(a.Src, "", nil),
+		ErrReslTyp(a.Src, "", nil), ErrReslSpec(a.Src, "", nil), ErrUnify(a.Src, ""),
+		ErrLayout(a.Src, a, nil), ErrEval(a.Src, "", nil), ErrUserErr(a.Src, "", nil),
+		ErrFailed(a.Src, "", nil),
+	}
+	for _, e := range all {
+		if LookupCode(e.Code) == nil {
+			t.Errorf("code %d of %s missing from catalog", e.Code, e.Name)
+		}
+	}
+	if c := LookupCode(111); c == nil || c.Title != "unterminated tree" {
+		t.Errorf("lookup 111 got %v", c)
+	}
+	if c := LookupCode(999); c != nil {
+		t.Errorf("lookup 999 want nil got %v", c)
+	}
+	err := fmt.Errorf("wrapped: %w", ErrEval(a.Src, "x", ErrUserErr(a.Src, "y", nil)))
+	if !HasCode(err, 600) || !HasCode(err, 530) || HasCode(err, 510) {
+		t.Errorf("has code failed for %v", err)
+	}
+}
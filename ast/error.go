@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -32,6 +33,21 @@ func (e *Error) Error() string {
 	return b.String()
 }
 
+// HasCode returns whether err or any error in its cause chain is an ast error with code.
+func HasCode(err error, code uint) bool {
+	for err != nil {
+		var e *Error
+		if !errors.As(err, &e) {
+			return false
+		}
+		if e.Code == code {
+			return true
+		}
+		err = e.Err
+	}
+	return false
+}
+
 func ErrTokStart(t Tok) *Error {
 	return &Error{Src: t.Src, Code: 101, Name: "unexpected token start",
 		Help: fmt.Sprintf("at input %q", t.String())}
@@ -110,6 +126,10 @@ func ErrEval(s Src, name string, err error) *Error {
 func ErrUserErr(s Src, name string, err error) *Error {
 	return &Error{Src: s, Code: 600, Name: name, Err: err}
 }
+func ErrFailed(s Src, name string, err error) *Error {
+	name = fmt.Sprintf("%s failed", name)
+	return &Error{Src: s, Code: 601, Name: name, Err: err}
+}
 
 func parens(k knd.Kind) (rune, rune) {
 	switch k {
@@ -0,0 +1,176 @@
+package ast
+
+import (
+	"errors"
+	"io"
+
+	"xelf.org/xelf/knd"
+)
+
+// Bad returns whether n is an error placeholder returned by the recovering scanner.
+// Bad nodes have the void kind and hold the offending token. Valid asts never have the void kind.
+func (n Ast) Bad() bool { return n.Kind == knd.Void }
+
+// ReadAllRecover reads all asts from r recovering from errors, see ScanAllRecover.
+func ReadAllRecover(r io.Reader, name string) ([]Ast, []*Error) {
+	return ScanAllRecover(NewLexer(r, name))
+}
+
+// ScanAllRecover scans all asts from l and recovers from syntax errors. It returns a best-effort
+// ast for the whole input, with bad nodes in place of invalid tokens, and all errors in input
+// order. Unterminated trees are closed at the end of input or by the closing token of an outer tree.
+func ScanAllRecover(l *Lexer) (res []Ast, _ []*Error) {
+	s := &recov{l: l}
+	for {
+		t, ok := s.tok()
+		if !ok {
+			break
+		}
+		if s.isEnd(t) {
+			s.err(ErrTokStart(t))
+			res = append(res, bad(t))
+			continue
+		}
+		res = append(res, s.rest(t))
+	}
+	return res, s.errs
+}
+
+type recov struct {
+	l    *Lexer
+	errs []*Error
+	peek *Tok
+	ends []rune
+}
+
+func (s *recov) err(e *Error) { s.errs = append(s.errs, e) }
+
+// tok returns the next token and false at the end of input. Tokens with lexer errors are returned
+// as bad tokens, they never hold control runes.
+func (s *recov) tok() (Tok, bool) {
+	if t := s.peek; t != nil {
+		s.peek = nil
+		return *t, true
+	}
+	t, err := s.l.Tok()
+	if err == nil {
+		return t, true
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		s.err(e)
+		t.Kind = knd.Void
+		return t, true
+	}
+	if !errors.Is(err, io.EOF) {
+		s.err(&Error{Src: t.Src, Code: 100, Name: "read failed", Err: err})
+	}
+	return t, false
+}
+
+// isEnd returns whether t is any closing token.
+func (s *recov) isEnd(t Tok) bool {
+	switch t.Rune {
+	case ')', ']', '}', '>':
+		return true
+	}
+	return false
+}
+
+// closesOuter returns whether r closes any tree enclosing the current one.
+func (s *recov) closesOuter(r rune) bool {
+	for i := len(s.ends) - 2; i >= 0; i-- {
+		if s.ends[i] == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *recov) rest(t Tok) Ast {
+	res := Ast{Tok: t}
+	if t.Kind&(knd.Idxr|knd.Keyr|knd.Typ|knd.Call) == 0 {
+		return res
+	}
+	_, end := parens(t.Kind)
+	if end == 0 {
+		return res
+	}
+	s.ends = append(s.ends, end)
+	defer func() { s.ends = s.ends[:len(s.ends)-1] }()
+	var sep bool
+	for {
+		t, ok := s.tok()
+		if !ok {
+			s.err(ErrTreeTerm(res.Tok))
+			res.Src.End = t.Src.Pos
+			return res
+		}
+		if t.Rune == end {
+			res.Src.End = t.Src.End
+			return res
+		}
+		if s.isEnd(t) {
+			if s.closesOuter(t.Rune) {
+				// leave the token for the outer tree
+				s.err(ErrTreeTerm(res.Tok))
+				res.Src.End = t.Src.Pos
+				s.peek = &t
+				return res
+			}
+			s.err(ErrTokStart(t))
+			res.Seq = append(res.Seq, bad(t))
+			continue
+		}
+		switch t.Rune {
+		case ',':
+			if !sep {
+				s.err(ErrInvalidSep(t))
+				res.Seq = append(res.Seq, bad(t))
+			}
+			sep = false
+			continue
+		case ':', ';':
+			s.err(ErrInvalidTag(t))
+			res.Seq = append(res.Seq, bad(t))
+			sep = false
+			continue
+		}
+		a := s.rest(t)
+		sep = true
+		if t, ok = s.tok(); !ok {
+			res.Seq = append(res.Seq, a)
+			continue
+		}
+		if t.Kind != knd.Tag {
+			res.Seq = append(res.Seq, a)
+			s.peek = &t
+			continue
+		}
+		switch a.Kind {
+		case knd.Sym, knd.Char, knd.Num:
+		default:
+			s.err(ErrInvalidTag(t))
+			res.Seq = append(res.Seq, a, bad(t))
+			sep = false
+			continue
+		}
+		tt := Ast{Tok: t, Seq: []Ast{a}}
+		tt.Src.Pos = a.Src.Pos
+		if t, ok = s.tok(); ok {
+			if tt.Rune == ';' || !valStart(t) {
+				s.peek = &t
+			} else {
+				b := s.rest(t)
+				tt.Seq = append(tt.Seq, b)
+				tt.Src.End = b.Src.End
+			}
+		}
+		res.Seq = append(res.Seq, tt)
+	}
+}
+
+func bad(t Tok) Ast {
+	t.Kind = knd.Void
+	return Ast{Tok: t}
+}
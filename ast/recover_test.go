@@ -0,0 +1,67 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScanAllRecover(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		errs []string
+	}{
+		{"[1 2] (a b:c)", "[1 2] (a b:c)", nil},
+		{"[1 00 2]", "[1 0 0 2]", []string{":1:3: adjacent zeros E102"}},
+		{"{:0 a:1}", "{: 0 a:1}", []string{":1:1: invalid tag E113"}},
+		{"{a:0:}", "{a:0 :}", []string{":1:4: invalid tag E113"}},
+		{"([1 2]:3 x)", "([1 2] : 3 x)", []string{":1:6: invalid tag E113"}},
+		{"(a ,, b)", "(a , b)", []string{":1:4: invalid separator E112"}},
+		{"(a [b c)", "(a [b c])", []string{":1:3: unterminated tree E111"}},
+		{"(a b", "(a b)", []string{":1:0: unterminated tree E111"}},
+		{"a ] (b}) c", "a ] (b }) c", []string{
+			":1:2: unexpected token start E101",
+			":1:6: unexpected token start E101",
+		}},
+		{"(a 'b\n c) d", "(a 'b c) d", []string{":1:3: unterminated string E105"}},
+		{"(a \\ b) (c", "(a \\ b) (c)", []string{
+			":1:3: unexpected token start E101",
+			":1:8: unterminated tree E111",
+		}},
+	}
+	for _, test := range tests {
+		got, errs := ReadAllRecover(strings.NewReader(test.raw), "")
+		strs := make([]string, 0, len(got))
+		for _, a := range got {
+			strs = append(strs, a.String())
+		}
+		if res := strings.Join(strs, " "); res != test.want {
+			t.Errorf("%q want ast %s got %s", test.raw, test.want, res)
+		}
+		var msgs []string
+		for _, e := range errs {
+			msgs = append(msgs, strings.SplitN(e.Error(), "\n", 2)[0])
+		}
+		if fmt.Sprint(msgs) != fmt.Sprint(test.errs) {
+			t.Errorf("%q want errors %q got %q", test.raw, test.errs, msgs)
+		}
+	}
+}
+
+func TestRecoverBad(t *testing.T) {
+	got, errs := ReadAllRecover(strings.NewReader("(a {b:1 : c)"), "")
+	if len(got) != 1 || len(errs) != 2 {
+		t.Fatalf("want one ast and two errors got %v %v", got, errs)
+	}
+	keyr := got[0].Seq[1]
+	if keyr.Src.End != (Pos{1, 11}) {
+		t.Errorf("want keyr end at close paren got %v", keyr.Src.End)
+	}
+	if n := keyr.Seq[1]; !n.Bad() || n.Rune != ':' {
+		t.Errorf("want bad tag node got %v", n)
+	}
+	if keyr.Seq[0].Bad() || keyr.Seq[2].Bad() {
+		t.Errorf("want good nodes around bad node got %v", keyr.Seq)
+	}
+}
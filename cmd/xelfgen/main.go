@@ -0,0 +1,116 @@
+// Command xelfgen generates code from xelf module declarations.
+//
+// Usage:
+//
+//	xelfgen go [-pkg path] [-o file] [-mod name] [-pkgs mod=path,...] file.xelf
+//	xelfgen ts [-o file] [-mod name] [-pkgs mod=path,...] [-rt file] file.xelf
+//
+// The go subcommand writes go declarations for all types of a module declared in file.
+// The ts subcommand writes typescript declarations and codec functions instead. Its -rt flag
+// writes the typescript runtime helpers to the given file. The runtime is imported as './xelf'
+// unless another path is provided for the module xelf with the -pkgs flag.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/gen"
+	"xelf.org/xelf/gen/gengo"
+	"xelf.org/xelf/gen/gents"
+	"xelf.org/xelf/lib/extlib"
+	"xelf.org/xelf/mod"
+	"xelf.org/xelf/typ"
+)
+
+type writer func(g *gen.Gen, w io.Writer, ts []typ.Type) error
+
+var cmds = map[string]writer{
+	"go": gengo.WriteFile,
+	"ts": gents.WriteFile,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: xelfgen go|ts [flags] file.xelf\n")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("xelfgen: ")
+	if len(os.Args) < 2 {
+		usage()
+	}
+	write := cmds[os.Args[1]]
+	if write == nil {
+		usage()
+	}
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	pkg := fs.String("pkg", "", "package path or name of the generated code")
+	out := fs.String("o", "", "output file, defaults to stdout")
+	name := fs.String("mod", "", "module name, required if the file declares more than one module")
+	pkgs := fs.String("pkgs", "", "comma separated list of mod=path package paths for other modules")
+	rt := fs.String("rt", "", "output file for the typescript runtime helpers")
+	fs.Parse(os.Args[2:])
+	if fs.NArg() != 1 {
+		usage()
+	}
+	if *rt != "" {
+		if err := os.WriteFile(*rt, []byte(gents.Runtime), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	path := fs.Arg(0)
+	env := mod.NewLoaderEnv(extlib.Std, mod.FileMods(filepath.Dir(path)))
+	m, err := findMod(env, path, *name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ts, err := gen.Types(m)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *pkg == "" {
+		*pkg = m.Name
+	}
+	g := gen.New(m.Name, *pkg)
+	for _, kv := range strings.Split(*pkgs, ",") {
+		if idx := strings.IndexByte(kv, '='); idx > 0 {
+			g.Pkgs[kv[:idx]] = kv[idx+1:]
+		}
+	}
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(g, w, ts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func findMod(env exp.Env, path, name string) (*exp.Mod, error) {
+	ms, err := gen.ReadMods(env, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		if name == "" && len(ms) == 1 || m.Name == name {
+			return m, nil
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("want one module in %s got %d", path, len(ms))
+	}
+	return nil, fmt.Errorf("module %s not found in %s", name, path)
+}
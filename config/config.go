@@ -0,0 +1,179 @@
+// Package config loads xelf config files with module imports, layered overrides and variables.
+//
+// A config file is a xelf program that usually results in a keyr value. Override files are
+// evaluated the same way and their result is applied as delta to the base config. Environment
+// variables are available as env.NAME symbols, if they are allowed in the loader variable env.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/mod"
+	"xelf.org/xelf/typ"
+)
+
+// Loader evaluates config files and decodes them into go values.
+type Loader struct {
+	// Env is the base program environment and defaults to the std builtins.
+	Env exp.Env
+	// Mods are the module loaders, a file loader for the config directory is used by default.
+	Mods []mod.Loader
+	// Vars is the variable env used for env.NAME symbols, no variables are available if nil.
+	Vars *VarEnv
+	// Reg is used to decode config values and defaults to the global registry.
+	Reg *lit.Regs
+}
+
+// New returns a new loader that allows the os environment variables matching allow.
+func New(allow ...string) *Loader {
+	return &Loader{Vars: &VarEnv{Allow: allow}}
+}
+
+// Eval evaluates the config file at path and returns the result or an error.
+func (l *Loader) Eval(path string) (lit.Val, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	as, err := ast.ReadAll(f, path)
+	if err != nil {
+		return nil, err
+	}
+	x, err := exp.ParseAll(as)
+	if err != nil {
+		return nil, err
+	}
+	p := exp.NewProg(l.env(path))
+	p.File.URL = path
+	return p.Run(x, nil)
+}
+
+// Load evaluates the config file at path, applies all existing override files in order and
+// returns the result or an error. Overrides must evaluate to a keyr, that is used as delta.
+// Missing override files are ignored, so that optional local overrides can always be passed.
+func (l *Loader) Load(path string, overrides ...string) (lit.Val, error) {
+	val, err := l.Eval(path)
+	if err != nil {
+		return nil, err
+	}
+	mut := val.Mut()
+	for _, o := range overrides {
+		if _, err := os.Stat(o); os.IsNotExist(err) {
+			continue
+		}
+		ov, err := l.Eval(o)
+		if err != nil {
+			return nil, err
+		}
+		d, err := toDelta(ov)
+		if err != nil {
+			return nil, fmt.Errorf("config override %s: %v", o, err)
+		}
+		mut, err = lit.Apply(mut, d)
+		if err != nil {
+			return nil, fmt.Errorf("config override %s: %v", o, err)
+		}
+	}
+	return mut, nil
+}
+
+// LoadInto loads the config file with overrides like Load and decodes the result into ptr.
+func (l *Loader) LoadInto(ptr interface{}, path string, overrides ...string) error {
+	val, err := l.Load(path, overrides...)
+	if err != nil {
+		return err
+	}
+	reg := l.Reg
+	if reg == nil {
+		reg = lit.GlobalRegs()
+	}
+	mut, err := lit.Proxy(reg, ptr)
+	if err != nil {
+		return err
+	}
+	if err = mut.Assign(val); err != nil {
+		return fmt.Errorf("config %s: %v", path, err)
+	}
+	return nil
+}
+
+func (l *Loader) env(path string) exp.Env {
+	par := l.Env
+	if par == nil {
+		par = exp.Builtins(lib.Std)
+	}
+	if l.Vars != nil {
+		vars := *l.Vars
+		vars.Par = par
+		par = &vars
+	}
+	mods := l.Mods
+	if mods == nil {
+		mods = []mod.Loader{mod.FileMods(filepath.Dir(path))}
+	}
+	return mod.NewLoaderEnv(par, mods...)
+}
+
+func toDelta(v lit.Val) (d lit.Delta, err error) {
+	k, ok := lit.Unwrap(v).(lit.Keyr)
+	if !ok {
+		return nil, fmt.Errorf("expect keyr override got %s", v.Type())
+	}
+	err = k.IterKey(func(key string, v lit.Val) error {
+		d = append(d, lit.KeyVal{Key: key, Val: v})
+		return nil
+	})
+	return d, err
+}
+
+// VarEnv is a restricted environment that resolves env.NAME symbols to variables.
+// Variables that are allowed but not set resolve to null.
+type VarEnv struct {
+	Par exp.Env
+	// Allow holds allowed variable names, a name ending in '*' allows all names with that prefix.
+	Allow []string
+	// Get returns a variable and whether it is set, it defaults to os.LookupEnv.
+	Get func(string) (string, bool)
+}
+
+func (e *VarEnv) Parent() exp.Env { return e.Par }
+
+func (e *VarEnv) Lookup(s *exp.Sym, p cor.Path, eval bool) (lit.Val, error) {
+	if len(p) != 2 || p[0].Key != "env" || p[1].Sep() != '.' || !strings.HasPrefix(s.Sym, "env.") {
+		return e.Par.Lookup(s, p, eval)
+	}
+	// path keys are lowercase so we use the symbol for the variable name
+	name := s.Sym[4:]
+	if !e.allowed(name) {
+		return nil, fmt.Errorf("variable %s is not allowed", name)
+	}
+	get := e.Get
+	if get == nil {
+		get = os.LookupEnv
+	}
+	val, ok := get(name)
+	if !ok {
+		s.Update(typ.Opt(typ.Str), e, p)
+		return lit.Null{}, nil
+	}
+	s.Update(typ.Str, e, p)
+	return lit.Str(val), nil
+}
+
+func (e *VarEnv) allowed(name string) bool {
+	for _, a := range e.Allow {
+		if a == name || strings.HasSuffix(a, "*") && strings.HasPrefix(name, a[:len(a)-1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type App struct {
+	Name  string
+	Port  int
+	DB    DB `json:"db"`
+	Debug bool
+	Tags  []string
+}
+
+type DB struct {
+	Host string
+	User string
+}
+
+func TestLoadInto(t *testing.T) {
+	tests := []struct {
+		vars      map[string]string
+		overrides []string
+		want      App
+	}{
+		{nil, nil, App{"shop", 8080, DB{"localhost", "app"}, true, []string{"base"}}},
+		{map[string]string{"APP_USER": "admin"}, nil,
+			App{"shop", 8080, DB{"localhost", "admin"}, true, []string{"base"}}},
+		{map[string]string{"APP_USER": "admin"},
+			[]string{"testdata/prod.xelf", "testdata/local.xelf"},
+			App{"shop", 9090, DB{"db.prod", "admin"}, false, []string{"prod", "web"}}},
+	}
+	for _, test := range tests {
+		l := New("APP_*")
+		vars := test.vars
+		l.Vars.Get = func(k string) (string, bool) {
+			v, ok := vars[k]
+			return v, ok
+		}
+		var got App
+		err := l.LoadInto(&got, "testdata/app.xelf", test.overrides...)
+		if err != nil {
+			t.Errorf("load %v: %v", test.vars, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("load %v want %+v got %+v", test.vars, test.want, got)
+		}
+	}
+}
+
+func TestLoadErr(t *testing.T) {
+	tests := []struct {
+		allow []string
+		want  string
+	}{
+		{nil, "variable HOME is not allowed"},
+		{[]string{"HOME"}, "testdata/bad.xelf:1:"},
+	}
+	for _, test := range tests {
+		l := New(test.allow...)
+		l.Vars.Get = func(string) (string, bool) { return "/root", true }
+		_, err := l.Load("testdata/bad.xelf")
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("want error %q got %v", test.want, err)
+		}
+	}
+}
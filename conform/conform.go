@@ -0,0 +1,221 @@
+// Package conform reads and runs data-driven conformance tests for xelf semantics.
+//
+// Test files are xelf sources with one test form per case:
+//
+//	(test add_ints input:(add 1 2) want:3)
+//	(test arg_sel input:(add $a 1) arg:{a:1} want:2 typ:<num>)
+//	(test no_spec input:(foo 1) err:E510)
+//
+// The input expression is resolved and evaluated in a fresh program with the optional arg as
+// program argument. The result is compared with want in plain xelf format and its type with the
+// optional typ. Cases with err expect an error with that code anywhere in the error chain.
+// The files only use xelf syntax, so that other runtimes can run the same suite.
+package conform
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Case is a conformance test case.
+type Case struct {
+	Name  string
+	Src   ast.Src
+	Input ast.Ast
+	// Arg is the optional program argument.
+	Arg lit.Val
+	// Want is the expected result in plain xelf format.
+	Want string
+	// Typ is the optional expected result type.
+	Typ string
+	// Err is the expected error code or zero.
+	Err uint
+}
+
+// Read reads and returns all test cases from the named reader r or an error.
+func Read(r io.Reader, name string) ([]*Case, error) {
+	as, err := ast.ReadAll(r, name)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Case, 0, len(as))
+	for _, a := range as {
+		c, err := readCase(a)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// ReadFile reads and returns all test cases from the file at path or an error.
+func ReadFile(path string) ([]*Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f, path)
+}
+
+func readCase(a ast.Ast) (*Case, error) {
+	if a.Kind != knd.Call || len(a.Seq) < 3 || a.Seq[0].Raw != "test" {
+		return nil, fmt.Errorf("%s: expect test form", a.Src)
+	}
+	c := &Case{Src: a.Src}
+	switch n := a.Seq[1]; n.Kind {
+	case knd.Sym:
+		c.Name = n.Raw
+	case knd.Char:
+		name, err := cor.Unquote(n.Raw)
+		if err != nil {
+			return nil, ast.ErrInvalid(n, knd.Char, err)
+		}
+		c.Name = name
+	default:
+		return nil, ast.ErrExpectSym(n)
+	}
+	var hasInput, hasWant bool
+	for _, t := range a.Seq[2:] {
+		if t.Kind != knd.Tag || len(t.Seq) != 2 {
+			return nil, ast.ErrExpectTag(t)
+		}
+		v := t.Seq[1]
+		switch key := t.Seq[0].Raw; key {
+		case "input":
+			c.Input, hasInput = v, true
+		case "arg":
+			arg, err := lit.ParseVal(v)
+			if err != nil {
+				return nil, err
+			}
+			c.Arg = arg
+		case "want":
+			want, err := lit.ParseVal(v)
+			if err != nil {
+				return nil, err
+			}
+			c.Want, hasWant = bfr.String(want), true
+		case "typ":
+			t, err := typ.ParseAst(v)
+			if err != nil {
+				return nil, err
+			}
+			c.Typ = t.String()
+		case "err":
+			code, err := parseCode(v)
+			if err != nil {
+				return nil, err
+			}
+			c.Err = code
+		default:
+			return nil, fmt.Errorf("%s: unknown test tag %s", t.Src, key)
+		}
+	}
+	if !hasInput {
+		return nil, fmt.Errorf("%s: test %s without input", a.Src, c.Name)
+	}
+	if hasWant == (c.Err != 0) {
+		return nil, fmt.Errorf("%s: test %s must have either want or err", a.Src, c.Name)
+	}
+	return c, nil
+}
+
+// parseCode parses error codes written as symbol like E510 or as plain number.
+func parseCode(a ast.Ast) (uint, error) {
+	raw := a.Raw
+	if a.Kind == knd.Sym && len(raw) > 1 && raw[0] == 'E' {
+		raw = raw[1:]
+	} else if a.Kind != knd.Num {
+		return 0, fmt.Errorf("%s: invalid error code %s", a.Src, a.Raw)
+	}
+	n, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("%s: invalid error code %s", a.Src, a.Raw)
+	}
+	return uint(n), nil
+}
+
+// Result is the outcome of running a test case.
+type Result struct {
+	Case *Case
+	// Got is the result in plain xelf format or the error.
+	Got string
+	// Fail describes why the case failed and is empty for passed cases.
+	Fail string
+}
+
+// Runner runs test cases through a new program for each case.
+type Runner struct {
+	// Env is the program environment and defaults to the standard library.
+	Env exp.Env
+}
+
+// Run runs test case c and returns the result.
+func (r *Runner) Run(c *Case) *Result {
+	res := &Result{Case: c}
+	env := r.Env
+	if env == nil {
+		env = lib.Std
+	}
+	x, err := exp.ParseAst(c.Input)
+	var v lit.Val
+	if err == nil {
+		v, err = exp.NewProg(env).Run(x, c.Arg)
+	}
+	if err != nil {
+		res.Got = err.Error()
+		if c.Err == 0 {
+			res.Fail = fmt.Sprintf("unexpected error: %v", err)
+		} else if !ast.HasCode(err, c.Err) {
+			res.Fail = fmt.Sprintf("want error E%d got: %v", c.Err, err)
+		}
+		return res
+	}
+	res.Got = bfr.String(v)
+	if c.Err != 0 {
+		res.Fail = fmt.Sprintf("want error E%d got %s", c.Err, res.Got)
+	} else if res.Got != c.Want {
+		res.Fail = fmt.Sprintf("want %s got %s", c.Want, res.Got)
+	} else if c.Typ != "" {
+		if t := v.Type().String(); t != c.Typ {
+			res.Fail = fmt.Sprintf("want type %s got %s", c.Typ, t)
+		}
+	}
+	return res
+}
+
+// RunFiles runs all test cases in the files matching the glob pattern and returns the results in
+// file order or an error if a file could not be read.
+func (r *Runner) RunFiles(pattern string) ([]*Result, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var res []*Result
+	for _, path := range paths {
+		cs, err := ReadFile(path)
+		if err != nil {
+			return res, err
+		}
+		for _, c := range cs {
+			res = append(res, r.Run(c))
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package conform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuite(t *testing.T) {
+	res, err := new(Runner).RunFiles("testdata/*.xelf")
+	if err != nil {
+		t.Fatalf("run suite: %v", err)
+	}
+	if len(res) == 0 {
+		t.Fatalf("no test cases found")
+	}
+	for _, r := range res {
+		if r.Fail != "" {
+			t.Errorf("%s %s: %s", r.Case.Src, r.Case.Name, r.Fail)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		raw string
+		err string
+	}{
+		{`(test a input:1 want:1)`, ""},
+		{`(test 'b c' input:1 err:E510)`, ""},
+		{`(foo a input:1 want:1)`, "expect test form"},
+		{`(test a want:1)`, "without input"},
+		{`(test a input:1)`, "either want or err"},
+		{`(test a input:1 want:1 err:E510)`, "either want or err"},
+		{`(test a input:1 err:X1)`, "invalid error code"},
+		{`(test a input:1 foo:1)`, "unknown test tag"},
+	}
+	for _, test := range tests {
+		_, err := Read(strings.NewReader(test.raw), "")
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("read %s: %v", test.raw, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("read %s want error %q got %v", test.raw, test.err, err)
+		}
+	}
+	r := new(Runner)
+	cs, _ := Read(strings.NewReader(`(test a input:(add 1 2) want:4) (test b input:1 err:E510)`), "")
+	for _, c := range cs {
+		if res := r.Run(c); res.Fail == "" {
+			t.Errorf("want %s to fail", c.Name)
+		}
+	}
+}
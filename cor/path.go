@@ -120,7 +120,7 @@ func addSeg(p Path, s string) (Path, string, error) {
 	}
 	idx, raw, rest := checkSeg(s, true)
 	if idx {
-		res.Idx, _ = strconv.Atoi(s)
+		res.Idx, _ = strconv.Atoi(raw)
 	} else if raw != "" {
 		res.Key = strings.ToLower(raw)
 	} else { // empty
@@ -144,6 +144,6 @@ func checkSeg(s string, split bool) (idx bool, raw, rest string) {
 			other = true
 		}
 	}
-	idx = idx && !other && s != "-"
+	idx = idx && !other && raw != "-"
 	return
 }
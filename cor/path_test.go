@@ -0,0 +1,29 @@
+package cor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Path
+	}{
+		{"a", Path{{Key: "a"}}},
+		{"points.1.x", Path{{Key: "points"}, {Idx: 1, Sel: '.'}, {Key: "x", Sel: '.'}}},
+		{"a/12/b", Path{{Key: "a"}, {Idx: 12, Sel: '/'}, {Key: "b", Sel: '/'}}},
+		{"a.-1.b", Path{{Key: "a"}, {Idx: -1, Sel: '.'}, {Key: "b", Sel: '.'}}},
+		{"a.-.b", Path{{Key: "a"}, {Key: "-", Sel: '.'}, {Key: "b", Sel: '.'}}},
+	}
+	for _, test := range tests {
+		got, err := ParsePath(test.raw)
+		if err != nil {
+			t.Errorf("parse %s: %v", test.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parse %s want %#v got %#v", test.raw, test.want, got)
+		}
+	}
+}
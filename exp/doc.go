@@ -0,0 +1,147 @@
+package exp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Doc holds the documentation of a spec or module.
+type Doc struct {
+	// Summary is a short description of what the spec or module does.
+	Summary string
+	// Params documents named spec parameters in declaration order.
+	Params []ParamDoc
+	// Examples holds code examples with their expected result.
+	Examples []Example
+	// Deprecated marks a deprecated spec and should say what to use instead.
+	Deprecated string
+}
+
+// ParamDoc is the documentation of a named spec parameter.
+type ParamDoc struct {
+	Name string
+	Doc  string
+}
+
+// Example is a code example and its expected result in xelf format.
+type Example struct {
+	Code string
+	Want string
+}
+
+// Documenter is an optional interface for specs that provide their own documentation.
+type Documenter interface {
+	Doc() *Doc
+}
+
+// Docs maps spec names to the documentation of specs that do not implement Documenter.
+type Docs map[string]*Doc
+
+// AddMap adds all documentation from m to this doc map.
+func (ds Docs) AddMap(m Docs) Docs {
+	for k, d := range m {
+		ds[k] = d
+	}
+	return ds
+}
+
+// Spec returns the documentation for spec s or nil.
+func (ds Docs) Spec(s Spec) *Doc {
+	if r := UnwrapSpec(s); r != nil && r.Spec != nil {
+		s = r.Spec
+	}
+	if d, ok := s.(Documenter); ok {
+		if doc := d.Doc(); doc != nil {
+			return doc
+		}
+	}
+	return ds[s.Type().Ref]
+}
+
+// Lookup looks up name in program p and returns its type and documentation or an error.
+// The name can be a module name, a qualified module declaration or a spec symbol.
+// The returned doc is nil for values without documentation.
+func (ds Docs) Lookup(p *Prog, name string) (typ.Type, *Doc, error) {
+	if m := p.File.Refs.Find(name); m != nil {
+		var doc *Doc
+		if m.Doc != "" {
+			doc = &Doc{Summary: m.Doc}
+		}
+		return m.Decl.Type(), doc, nil
+	}
+	x, err := p.Resl(p, &Sym{Sym: name}, typ.Void)
+	if err != nil {
+		return typ.Void, nil, err
+	}
+	l, ok := x.(*Lit)
+	if !ok {
+		return typ.Void, nil, fmt.Errorf("no documentation for %s", name)
+	}
+	if s := UnwrapSpec(lit.Unwrap(l.Val)); s != nil {
+		return s.Decl, ds.Spec(s), nil
+	}
+	return l.Val.Type(), nil, nil
+}
+
+// Markdown renders an index of all specs in b with their summary as markdown list.
+func (ds Docs) Markdown(b Builtins) string {
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " * `%s`", k)
+		if d := ds.Spec(b[k]); d != nil && d.Summary != "" {
+			fmt.Fprintf(&sb, " %s", firstLine(d.Summary))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Markdown renders the documentation for name with type t as markdown.
+func (d *Doc) Markdown(name string, t typ.Type) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", name)
+	if t != typ.Void {
+		fmt.Fprintf(&b, "\t%s\n\n", t)
+	}
+	if d == nil {
+		return b.String()
+	}
+	if d.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(d.Summary))
+	}
+	if d.Deprecated != "" {
+		fmt.Fprintf(&b, "Deprecated: %s\n\n", strings.TrimSpace(d.Deprecated))
+	}
+	if len(d.Params) > 0 {
+		b.WriteString("Parameters:\n\n")
+		for _, p := range d.Params {
+			fmt.Fprintf(&b, " * `%s` %s\n", p.Name, p.Doc)
+		}
+		b.WriteByte('\n')
+	}
+	if len(d.Examples) > 0 {
+		b.WriteString("Examples:\n\n")
+		for _, e := range d.Examples {
+			fmt.Fprintf(&b, "\t%s\n\t# %s\n", e.Code, e.Want)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
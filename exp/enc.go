@@ -0,0 +1,400 @@
+package exp
+
+import (
+	"fmt"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Encode returns a typed literal encoding of the expression x resolved by program p or an error.
+// The encoding can be printed as xelf or json. Every node is a dict with a kind key:
+//
+//	{lit:<int> val:1}                       typed literal, the value is omitted for null
+//	{typ:<list|int>}                        type literal
+//	{spec:'add'}                            named spec literal
+//	{sym:'a' typ:<int>}                     symbol with resolved type
+//	{tag:'a' exp:…}                         tag with optional expression
+//	{tupl:<tupl|int> els:[…]}               tuple with resolved type
+//	{call:'add' sig:<form@add …> args:[…]}  call with signature and laid out arguments
+//
+// Types are updated with the program type bindings and remaining type variables are replaced by
+// their constraint. Json prints types as strings. Source positions are not encoded. Specs are
+// encoded by name and must resolve in the program, so anonymous funcs cannot be encoded.
+func Encode(p *Prog, x Exp) (lit.Val, error) {
+	e := encoder{p}
+	return e.exp(x)
+}
+
+// Decode returns the expression for the typed encoding v or an error. The result is bound to a
+// program by resolving it with Prog.Resl. Decoded calls look up their spec by name and keep their
+// signature and argument layout. Spec base resolution then only binds the arguments to the call
+// env without instantiating or unifying any types again.
+func Decode(v lit.Val) (Exp, error) {
+	d := decoder{make(map[string]typ.Type)}
+	return d.exp(v)
+}
+
+// decoder caches parsed types, because most encoded types are repeated many times.
+type decoder struct{ types map[string]typ.Type }
+
+func (d decoder) exp(v lit.Val) (Exp, error) {
+	if v == nil || v.Nil() {
+		return nil, nil
+	}
+	k, ok := lit.Unwrap(v).(lit.Keyr)
+	if !ok {
+		return nil, fmt.Errorf("expect encoded exp dict got %s", v)
+	}
+	switch {
+	case has(k, "lit"):
+		t, err := d.typ(k, "lit")
+		if err != nil {
+			return nil, err
+		}
+		if !has(k, "val") {
+			if t == typ.None {
+				return LitVal(lit.Null{}), nil
+			}
+			return LitVal(lit.AnyWrap(t)), nil
+		}
+		f, _ := k.Key("val")
+		if f.Type().Equal(t) {
+			return LitVal(f), nil
+		}
+		m := lit.Zero(t)
+		if err = m.Assign(f); err != nil {
+			return nil, fmt.Errorf("decode lit %s: %w", t, err)
+		}
+		return LitVal(m), nil
+	case has(k, "sym"):
+		name, err := str(k, "sym")
+		if err != nil {
+			return nil, err
+		}
+		t, err := d.typ(k, "typ")
+		if err != nil {
+			return nil, err
+		}
+		return &Sym{Sym: name, Res: t}, nil
+	case has(k, "typ"):
+		t, err := d.typ(k, "typ")
+		if err != nil {
+			return nil, err
+		}
+		return LitVal(t), nil
+	case has(k, "spec"):
+		// spec literals are resolved by name
+		name, err := str(k, "spec")
+		if err != nil {
+			return nil, err
+		}
+		return &Sym{Sym: name, Res: typ.Spec}, nil
+	case has(k, "tag"):
+		name, err := str(k, "tag")
+		if err != nil {
+			return nil, err
+		}
+		var x Exp
+		if has(k, "exp") {
+			f, _ := k.Key("exp")
+			if x, err = d.exp(f); err != nil {
+				return nil, err
+			}
+		}
+		return &Tag{Tag: name, Exp: x}, nil
+	case has(k, "tupl"):
+		t, err := d.typ(k, "tupl")
+		if err != nil {
+			return nil, err
+		}
+		els, err := d.list(k, "els")
+		if err != nil {
+			return nil, err
+		}
+		return &Tupl{Res: t, Els: els}, nil
+	case has(k, "call"):
+		name, err := str(k, "call")
+		if err != nil {
+			return nil, err
+		}
+		sig, err := d.typ(k, "sig")
+		if err != nil {
+			return nil, err
+		}
+		args, err := d.list(k, "args")
+		if err != nil {
+			return nil, err
+		}
+		return &Call{Sig: sig, Spec: &namedSpec{name, sig}, Args: args}, nil
+	}
+	return nil, fmt.Errorf("unexpected encoded exp %s", v)
+}
+
+// typ returns a fresh copy of the type literal or type string at key, so that decoded nodes never
+// share type bodies. Type strings are read from json and parsed only once.
+func (d decoder) typ(k lit.Keyr, key string) (typ.Type, error) {
+	f, err := k.Key(key)
+	if err != nil {
+		return typ.Void, err
+	}
+	if t, ok := lit.Unwrap(f).(typ.Type); ok {
+		return typ.Clone(t), nil
+	}
+	s, err := lit.ToStr(f)
+	if err != nil {
+		return typ.Void, err
+	}
+	t, ok := d.types[string(s)]
+	if !ok {
+		if t, err = typ.Parse(string(s)); err != nil {
+			return typ.Void, err
+		}
+		d.types[string(s)] = t
+	}
+	return typ.Clone(t), nil
+}
+
+func (d decoder) list(k lit.Keyr, key string) ([]Exp, error) {
+	f, err := k.Key(key)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := lit.Unwrap(f).(lit.Idxr)
+	if !ok {
+		if f.Nil() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("expect encoded %s list got %s", key, f)
+	}
+	res := make([]Exp, 0, l.Len())
+	err = l.IterIdx(func(i int, v lit.Val) error {
+		x, err := d.exp(v)
+		res = append(res, x)
+		return err
+	})
+	return res, err
+}
+
+func str(k lit.Keyr, key string) (string, error) {
+	f, err := k.Key(key)
+	if err != nil {
+		return "", err
+	}
+	s, err := lit.ToStr(f)
+	return string(s), err
+}
+
+func has(k lit.Keyr, key string) bool {
+	f, err := k.Key(key)
+	return err == nil && f != nil && !f.Nil()
+}
+
+// namedSpec is the spec of decoded calls. It looks up the named spec on resolution.
+type namedSpec struct {
+	name string
+	sig  typ.Type
+}
+
+func (s *namedSpec) Type() typ.Type { return s.sig }
+func (s *namedSpec) Resl(p *Prog, env Env, c *Call, h typ.Type) (Exp, error) {
+	sp := lookupSpec(env, s.name)
+	if sp == nil {
+		return c, fmt.Errorf("spec %s not found", s.name)
+	}
+	// the call keeps the named spec while resolving, so spec base only rebinds the arguments
+	x, err := sp.Resl(p, env, c, h)
+	c.Spec = sp
+	return x, err
+}
+func (s *namedSpec) Eval(p *Prog, c *Call) (lit.Val, error) {
+	return nil, fmt.Errorf("unresolved spec %s", s.name)
+}
+
+// rebindArgs binds the arguments of decoded call c to the call env without unifying any types.
+func (p *Prog) rebindArgs(c *Call) (err error) {
+	for i, a := range c.Args {
+		if a != nil {
+			if c.Args[i], err = p.rebind(c.Env, a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rebind binds symbols in the decoded expression x to env and resolves calls with named specs.
+func (p *Prog) rebind(env Env, x Exp) (_ Exp, err error) {
+	switch a := x.(type) {
+	case *Sym:
+		if a.Env == nil {
+			path, err := cor.ParsePath(a.Sym)
+			if err != nil {
+				return nil, ast.ErrReslSym(a.Src, a.Sym, err)
+			}
+			a.Update(a.Res, env, path)
+		}
+		r, err := a.Env.Lookup(a, a.Path, false)
+		if err != nil {
+			return nil, ast.ErrReslSym(a.Src, a.Sym, err)
+		}
+		if r != nil {
+			return LitSrc(r, a.Src), nil
+		}
+	case *Tag:
+		if a.Exp != nil {
+			a.Exp, err = p.rebind(env, a.Exp)
+		}
+	case *Tupl:
+		for i, el := range a.Els {
+			if a.Els[i], err = p.rebind(env, el); err != nil {
+				return nil, err
+			}
+		}
+	case *Call:
+		return p.Resl(env, a, typ.Void)
+	}
+	return x, err
+}
+
+type encoder struct{ p *Prog }
+
+func (e encoder) exp(x Exp) (lit.Val, error) {
+	switch a := x.(type) {
+	case nil:
+		return lit.Null{}, nil
+	case *Lit:
+		return e.lit(a)
+	case *Sym:
+		t, err := e.typ(a.Res)
+		if err != nil {
+			return nil, err
+		}
+		return node("sym", lit.Str(a.Sym), "typ", t), nil
+	case *Tag:
+		if a.Exp == nil {
+			return node("tag", lit.Str(a.Tag)), nil
+		}
+		v, err := e.exp(a.Exp)
+		if err != nil {
+			return nil, err
+		}
+		return node("tag", lit.Str(a.Tag), "exp", v), nil
+	case *Tupl:
+		t, err := e.typ(a.Res)
+		if err != nil {
+			return nil, err
+		}
+		els, err := e.list(a.Els)
+		if err != nil {
+			return nil, err
+		}
+		return node("tupl", t, "els", els), nil
+	case *Call:
+		if a.Spec == nil {
+			return nil, fmt.Errorf("cannot encode unresolved call %s", a)
+		}
+		env := a.Env
+		if env == nil {
+			env = e.p
+		}
+		if a.Sig.Ref == "" || lookupSpec(env, a.Sig.Ref) == nil {
+			return nil, fmt.Errorf("cannot encode call to anonymous spec %s", a.Sig)
+		}
+		sig, err := e.typ(a.Sig)
+		if err != nil {
+			return nil, err
+		}
+		args, err := e.list(a.Args)
+		if err != nil {
+			return nil, err
+		}
+		return node("call", lit.Str(a.Sig.Ref), "sig", sig, "args", args), nil
+	}
+	return nil, fmt.Errorf("unexpected exp %T", x)
+}
+
+func (e encoder) lit(a *Lit) (lit.Val, error) {
+	v := a.Val
+	if v == nil {
+		v = lit.Null{}
+	}
+	switch u := lit.Unwrap(v).(type) {
+	case typ.Type:
+		return e.typNode(u)
+	case *typ.Type:
+		return e.typNode(*u)
+	}
+	if s := UnwrapSpec(lit.Unwrap(v)); s != nil {
+		name := s.Decl.Ref
+		if name == "" || lookupSpec(e.p, name) == nil {
+			return nil, fmt.Errorf("cannot encode anonymous spec %s", s.Decl)
+		}
+		return node("spec", lit.Str(name)), nil
+	}
+	t, err := e.typ(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	if v.Nil() {
+		// null values are omitted, because tags with null values print as flags
+		return node("lit", t), nil
+	}
+	return node("lit", t, "val", v), nil
+}
+
+func (e encoder) typNode(t typ.Type) (lit.Val, error) {
+	s, err := e.typ(t)
+	if err != nil {
+		return nil, err
+	}
+	return node("typ", s), nil
+}
+
+func (e encoder) list(xs []Exp) (lit.Val, error) {
+	vals := make([]lit.Val, 0, len(xs))
+	for _, x := range xs {
+		v, err := e.exp(x)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return &lit.List{Typ: typ.List, Vals: vals}, nil
+}
+
+// typ returns the concrete type t as type literal. Bound type variables are replaced by their
+// binding and free variables by their constraint.
+func (e encoder) typ(t typ.Type) (lit.Val, error) {
+	t, err := e.p.Sys.Update(t)
+	if err != nil {
+		return nil, err
+	}
+	t, err = typ.Edit(typ.Clone(t), func(ed *typ.Editor) (typ.Type, error) {
+		r := ed.Type
+		if r.Kind&knd.Var == 0 {
+			return r, nil
+		}
+		r.Kind &^= knd.Var
+		r.ID = 0
+		if r.Kind&^knd.None == 0 {
+			r.Kind = knd.Any
+		}
+		return r, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func node(kvs ...interface{}) lit.Val {
+	d := &lit.Dict{}
+	for i := 0; i < len(kvs); i += 2 {
+		d.Keyed = append(d.Keyed, lit.KeyVal{Key: kvs[i].(string), Val: kvs[i+1].(lit.Val)})
+	}
+	return d
+}
@@ -0,0 +1,149 @@
+package exp_test
+
+import (
+	"strings"
+	"testing"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		raw  string
+		arg  string
+		want string
+	}{
+		{`(add 1 2)`, ``, `3`},
+		{`(cat 'a' (sep ',' 1 2) $x)`, `{x:'b'}`, `'a1,2b'`},
+		{`(with a:1 b:(add a 1) (add a b))`, ``, `3`},
+		{`(with 2 (mul . .))`, ``, `4`},
+		{`(if (lt $n 3) (list|int + 1 2) (list|int + 3))`, `{n:1}`, `[1 2]`},
+		{`(swt $n 1 'one' 2 'two' 'many')`, `{n:2}`, `'two'`},
+		{`(make list|int + 1 2)`, ``, `[1 2]`},
+		{`(with {a:1 b:[2 3]} (add .a .b.1))`, ``, `4`},
+		{`(fold [1 2 3] 0 add)`, ``, `6`},
+		{`(len 'abc')`, ``, `3`},
+	}
+	for _, test := range tests {
+		x, err := exp.Parse(test.raw)
+		if err != nil {
+			t.Errorf("parse %s failed: %v", test.raw, err)
+			continue
+		}
+		var arg lit.Val
+		if test.arg != "" {
+			arg, err = lit.Parse(test.arg)
+			if err != nil {
+				t.Errorf("parse arg %s failed: %v", test.arg, err)
+				continue
+			}
+		}
+		p := exp.NewProg(lib.Std)
+		p.Arg = arg
+		x, err = p.Resl(p, x, typ.Void)
+		if err != nil {
+			t.Errorf("resl %s failed: %v", test.raw, err)
+			continue
+		}
+		enc, err := exp.Encode(p, x)
+		if err != nil {
+			t.Errorf("encode %s failed: %v", test.raw, err)
+			continue
+		}
+		for _, json := range []bool{false, true} {
+			var raw string
+			if json {
+				b, err := bfr.JSON(enc)
+				if err != nil {
+					t.Errorf("encode json %s failed: %v", test.raw, err)
+					continue
+				}
+				raw = string(b)
+			} else {
+				raw = bfr.String(enc)
+			}
+			if strings.Contains(raw, "@") && !strings.Contains(raw, "form@") {
+				t.Errorf("encoding %s has type vars: %s", test.raw, raw)
+			}
+			v, err := lit.Parse(raw)
+			if err != nil {
+				t.Errorf("parse encoding %s failed: %v", raw, err)
+				continue
+			}
+			dec, err := exp.Decode(v)
+			if err != nil {
+				t.Errorf("decode %s failed: %v", raw, err)
+				continue
+			}
+			res, err := exp.NewProg(lib.Std).Run(dec, arg)
+			if err != nil {
+				t.Errorf("run decoded %s failed: %v\n%s", test.raw, err, raw)
+				continue
+			}
+			if got := bfr.String(res); got != test.want {
+				t.Errorf("run decoded %s want %s got %s", test.raw, test.want, got)
+			}
+		}
+	}
+}
+
+func TestEncodeAnon(t *testing.T) {
+	x, err := exp.Parse(`(fold [1 2 3] 0 (fn (add _ .1)))`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	p := exp.NewProg(lib.Std)
+	x, err = p.Resl(p, x, typ.Void)
+	if err != nil {
+		t.Fatalf("resl failed: %v", err)
+	}
+	if _, err = exp.Encode(p, x); err == nil {
+		t.Errorf("want error for anonymous func")
+	}
+}
+
+const benchRaw = `(with a:1 b:(add a 1) (if (lt a b) (cat 'x' (sep ',' a b)) (cat 'y' (fold [1 2 3] 0 add))))`
+
+func BenchmarkResl(b *testing.B) {
+	x, err := exp.Parse(benchRaw)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := exp.NewProg(lib.Std)
+		if _, err := p.Resl(p, x.Clone(), typ.Void); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeResl(b *testing.B) {
+	x, err := exp.Parse(benchRaw)
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := exp.NewProg(lib.Std)
+	if x, err = p.Resl(p, x, typ.Void); err != nil {
+		b.Fatal(err)
+	}
+	enc, err := exp.Encode(p, x)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec, err := exp.Decode(enc)
+		if err != nil {
+			b.Fatal(err)
+		}
+		p := exp.NewProg(lib.Std)
+		if _, err := p.Resl(p, dec, typ.Void); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -162,8 +162,12 @@ func (c *Call) Print(p *bfr.P) error {
 		p.Fmt(name)
 		p.Byte(' ')
 	}
-	for i, a := range c.Args {
-		if i != 0 {
+	var n int
+	for _, a := range c.Args {
+		if a == nil { // missing optional argument
+			continue
+		}
+		if n++; n > 1 {
 			p.Byte(' ')
 		}
 		err := a.Print(p)
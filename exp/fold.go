@@ -0,0 +1,99 @@
+package exp
+
+import (
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Pure is an optional interface for specs without side effects whose results only depend on
+// their arguments. Calls to pure specs with only literal arguments are folded ahead of time.
+type Pure interface {
+	Pure() bool
+}
+
+// Folder is an optional interface for specs that simplify calls with partially known arguments,
+// like conditionals with a literal condition. Fold is called with already folded arguments and
+// returns the simplified expression, the call itself if it cannot be simplified, or an error.
+type Folder interface {
+	Fold(p *Prog, c *Call) (Exp, error)
+}
+
+// Partial resolves x with the partially known program argument arg and folds the result.
+// Argument values that are null are treated as unknown and remain as symbols. The result can
+// later be evaluated for a complete argument by setting the program argument and calling Eval.
+func (p *Prog) Partial(x Exp, arg lit.Val) (Exp, error) {
+	p.Arg = arg
+	x, err := p.Resl(p, x, typ.Void)
+	if err != nil {
+		return nil, err
+	}
+	return p.Fold(x)
+}
+
+// Fold folds all calls of the resolved expression x that can be evaluated ahead of time and
+// returns the result or an error. Calls to pure specs that fail are left for evaluation, so that
+// errors still surface at run time and only if the call is actually evaluated. Function bodies
+// are not folded.
+func (p *Prog) Fold(x Exp) (Exp, error) {
+	switch a := x.(type) {
+	case *Tag:
+		if a.Exp != nil {
+			e, err := p.Fold(a.Exp)
+			if err != nil {
+				return nil, err
+			}
+			a.Exp = e
+		}
+	case *Tupl:
+		for i, el := range a.Els {
+			e, err := p.Fold(el)
+			if err != nil {
+				return nil, err
+			}
+			a.Els[i] = e
+		}
+	case *Call:
+		for i, arg := range a.Args {
+			if arg == nil {
+				continue
+			}
+			e, err := p.Fold(arg)
+			if err != nil {
+				return nil, err
+			}
+			a.Args[i] = e
+		}
+		spec := a.Spec
+		if r := UnwrapSpec(spec); r != nil && r.Spec != nil {
+			spec = r.Spec
+		}
+		if f, ok := spec.(Folder); ok {
+			return f.Fold(p, a)
+		}
+		if ps, ok := spec.(Pure); ok && ps.Pure() && constArgs(a.Args) {
+			if v, err := p.Eval(a.Env, a); err == nil {
+				return LitSrc(v, a.Src), nil
+			}
+		}
+	}
+	return x, nil
+}
+
+func constArgs(args []Exp) bool {
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case nil, *Lit:
+		case *Tag:
+			if a.Exp != nil && !constArgs([]Exp{a.Exp}) {
+				return false
+			}
+		case *Tupl:
+			if !constArgs(a.Els) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
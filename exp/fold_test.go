@@ -0,0 +1,85 @@
+package exp_test
+
+import (
+	"testing"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`(add 1 2)`, `3`},
+		{`(add 1 (mul 2 3) (sub 4 1))`, `10`},
+		{`(cat 'a' (sep '-' 'b' 'c'))`, `'ab-c'`},
+		{`(if true 1 2)`, `1`},
+		{`(if false 1 2)`, `2`},
+		{`(if false 1)`, `0`},
+		{`(if (eq 1 2) 'a' (lt 1 2) 'b' 'c')`, `'b'`},
+		{`(if (eq $x 2) 'a' true 'b' 'c')`, `(if (eq $x 2) 'a' 'b')`},
+		{`(if false 'a' (eq $x 2) 'b' 'c')`, `(if (eq $x 2) 'b' 'c')`},
+		{`(swt 2 1 'a' 2 'b' 'c')`, `'b'`},
+		{`(swt 3 1 'a' 2 'b' 'c')`, `'c'`},
+		{`(swt $x 1 'a' 'c')`, `(swt $x 1 'a' 'c')`},
+		{`(add $x (mul 2 3))`, `(add $x 6)`},
+		{`(with a:(add 1 2) (add a 1))`, `(with a:3 (add a 1))`},
+	}
+	for _, test := range tests {
+		x, err := exp.Parse(test.raw)
+		if err != nil {
+			t.Errorf("parse %s failed: %v", test.raw, err)
+			continue
+		}
+		p := exp.NewProg(lib.Std)
+		arg := &lit.Dict{Keyed: lit.Keyed{{Key: "x", Val: lit.AnyWrap(typ.Int)}}}
+		x, err = p.Partial(x, arg)
+		if err != nil {
+			t.Errorf("fold %s failed: %v", test.raw, err)
+			continue
+		}
+		if got := bfr.String(x); got != test.want {
+			t.Errorf("fold %s want %s got %s", test.raw, test.want, got)
+		}
+	}
+}
+
+func TestPartial(t *testing.T) {
+	x, err := exp.Parse(`(if (eq $tenant.plan 'pro') (mul $req.n $tenant.rate) $req.n)`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	tenant := &lit.Dict{Keyed: lit.Keyed{
+		{Key: "plan", Val: lit.Str("pro")},
+		{Key: "rate", Val: lit.Int(3)},
+	}}
+	p := exp.NewProg(lib.Std)
+	x, err = p.Partial(x, &lit.Dict{Keyed: lit.Keyed{
+		{Key: "tenant", Val: tenant},
+		{Key: "req", Val: lit.AnyWrap(typ.Obj("", typ.P("n", typ.Int)))},
+	}})
+	if err != nil {
+		t.Fatalf("partial failed: %v", err)
+	}
+	if got, want := x.String(), `(mul $req.n 3)`; got != want {
+		t.Errorf("partial want %s got %s", want, got)
+	}
+	for _, n := range []int64{2, 5} {
+		p.Arg = &lit.Dict{Keyed: lit.Keyed{
+			{Key: "tenant", Val: tenant},
+			{Key: "req", Val: &lit.Dict{Keyed: lit.Keyed{{Key: "n", Val: lit.Int(n)}}}},
+		}}
+		res, err := p.Eval(p, x)
+		if err != nil {
+			t.Fatalf("eval failed: %v", err)
+		}
+		if got := res.String(); got != lit.Int(n*3).String() {
+			t.Errorf("eval with %d got %s", n, got)
+		}
+	}
+}
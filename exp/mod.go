@@ -37,18 +37,46 @@ type Mod struct {
 	// Name is the declare module name.
 	Name string
 
+	// Doc is the module documentation.
+	Doc string
+
 	// Decl holds the exported module declarations, that are copied for each program.
 	Decl *lit.Obj
 }
 
 // ModRef represent a module reference with a possible alias the original import path.
+// Version is set for version qualified import paths like 'company.com/prod@v2'.
+// Use holds declaration names that are available as unqualified names in the referencing file,
+// a single '*' uses all declarations.
 type ModRef struct {
-	Alias string
-	Path  string
-	Pub   bool
+	Alias   string
+	Path    string
+	Version string
+	Pub     bool
+	Use     []string
 	*Mod
 }
 
+// Uses returns whether the declaration name is used unqualified.
+func (ref ModRef) Uses(name string) bool {
+	key := cor.Keyed(name)
+	for _, u := range ref.Use {
+		if u == "*" && ref.HasDecl(key) || u != "*" && cor.Keyed(u) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDecl returns whether the module declares name.
+func (m *Mod) HasDecl(name string) bool {
+	if m == nil || m.Decl == nil {
+		return false
+	}
+	pb, ok := m.Decl.Typ.Body.(*typ.ParamBody)
+	return ok && pb.FindKeyIndex(cor.Keyed(name)) >= 0
+}
+
 // Key returns the alias or module name.
 func (ref ModRef) Key() string {
 	if ref.Alias != "" {
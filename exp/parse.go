@@ -96,9 +96,15 @@ func ParseAst(a ast.Ast) (Exp, error) {
 		}
 		var e Exp
 		if len(a.Seq) > 1 {
-			e, err = ParseAst(a.Seq[1])
-			if err != nil {
-				return nil, err
+			if tag == "use" {
+				// use tags accept symbols as declaration names like use:[Prod Cat]
+				e = useNames(a.Seq[1])
+			}
+			if e == nil {
+				e, err = ParseAst(a.Seq[1])
+				if err != nil {
+					return nil, err
+				}
 			}
 		}
 		return &Tag{Tag: tag, Exp: e, Src: a.Src}, nil
@@ -128,3 +134,29 @@ func ParseAst(a ast.Ast) (Exp, error) {
 	}
 	return nil, ast.ErrUnexpected(a)
 }
+
+// useNames returns a list literal of names for a list of symbols or strings or nil.
+func useNames(a ast.Ast) Exp {
+	if a.Kind != knd.Idxr || len(a.Seq) == 0 {
+		return nil
+	}
+	vals := make(lit.Vals, 0, len(a.Seq))
+	for _, e := range a.Seq {
+		switch e.Kind {
+		case knd.Sym:
+			if !cor.IsName(e.Raw) {
+				return nil
+			}
+			vals = append(vals, lit.Char(e.Raw))
+		case knd.Char:
+			txt, err := cor.Unquote(e.Raw)
+			if err != nil {
+				return nil
+			}
+			vals = append(vals, lit.Char(txt))
+		default:
+			return nil
+		}
+	}
+	return LitSrc(&vals, a.Src)
+}
@@ -39,6 +39,10 @@ func (s *SpecBase) Resl(p *Prog, env Env, c *Call, h typ.Type) (Exp, error) {
 	if c.Env == nil {
 		c.Env = env
 	}
+	if _, ok := c.Spec.(*namedSpec); ok {
+		// decoded calls keep their signature and only need to bind the arguments
+		return c, p.rebindArgs(c)
+	}
 	ps := SigArgs(c.Sig)
 	n := len(ps)
 	vari := s.Decl.Kind&knd.Spec == knd.Func && n > 0 && ps[n-1].Kind&knd.List != 0
@@ -0,0 +1,174 @@
+package ext
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Evaler is the interface for go structs that implement a form using NewForm.
+type Evaler interface {
+	Eval(p *exp.Prog) (lit.Val, error)
+}
+
+// Form is a form spec implementation that uses a go struct for the arguments and evaluation.
+type Form struct {
+	exp.SpecBase
+	val    reflect.Value
+	fields []formField
+}
+
+type formField struct {
+	idx  []int
+	kind fieldKind
+	arg  int
+}
+
+type fieldKind int
+
+const (
+	fieldVal fieldKind = iota
+	fieldExp
+	fieldExps
+	fieldEnv
+	fieldCall
+)
+
+// NewForm reflects the struct or struct pointer val and returns a named form spec or an error.
+// The struct pointer must implement Evaler. The form parameters are derived from exported struct
+// fields using the same naming rules and json tags as lit.PrxReg. Fields of type exp.Exp and
+// []exp.Exp are not evaluated and use the parameter types exp and tupl|exp. Fields of type
+// exp.Env and *exp.Call are not parameters and are set to the call environment and call.
+// All other fields are evaluated and converted with lit.Conv.
+// Every call evaluates a copy of val, so that fields ignored with a '-' json tag can be used to
+// provide shared dependencies. The form result type is any.
+func NewForm(reg lit.Reg, name string, val interface{}) (*Form, error) {
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expect struct argument got %T", val)
+	}
+	t := v.Type()
+	if !reflect.PtrTo(t).Implements(refEvaler) {
+		return nil, fmt.Errorf("expect %s to implement ext.Evaler", t)
+	}
+	if reg == nil {
+		reg = lit.GlobalRegs()
+	}
+	pb := &typ.ParamBody{}
+	s := &Form{SpecBase: exp.SpecBase{Decl: typ.Type{Kind: knd.Form, Ref: name, Body: pb}}, val: v}
+	for i, n := 0, t.NumField(); i < n; i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		ff := formField{idx: f.Index, arg: len(pb.Params)}
+		var pt typ.Type
+		switch f.Type {
+		case refEnv:
+			ff.kind = fieldEnv
+		case refCall:
+			ff.kind = fieldCall
+		case refExp:
+			ff.kind, pt = fieldExp, typ.Exp
+		case refExps:
+			ff.kind, pt = fieldExps, typ.ElemTupl(typ.Exp)
+		}
+		if ff.kind == fieldEnv || ff.kind == fieldCall {
+			s.fields = append(s.fields, ff)
+			continue
+		}
+		key, ok := fieldKey(f)
+		if !ok {
+			continue
+		}
+		if ff.kind == fieldVal {
+			var err error
+			pt, err = reg.Reflect(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("form %s field %s: %v", name, f.Name, err)
+			}
+		}
+		s.fields = append(s.fields, ff)
+		pb.Params = append(pb.Params, typ.P(key, pt))
+	}
+	pb.Params = append(pb.Params, typ.P("", typ.Any))
+	return s, nil
+}
+
+func (s *Form) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	rv := reflect.New(s.val.Type())
+	e := rv.Elem()
+	e.Set(s.val)
+	for _, f := range s.fields {
+		fv := e.FieldByIndex(f.idx)
+		switch f.kind {
+		case fieldEnv:
+			fv.Set(reflect.ValueOf(&c.Env).Elem())
+			continue
+		case fieldCall:
+			fv.Set(reflect.ValueOf(c))
+			continue
+		}
+		a := c.Args[f.arg]
+		if a == nil {
+			continue
+		}
+		switch f.kind {
+		case fieldExp:
+			fv.Set(reflect.ValueOf(&a).Elem())
+		case fieldExps:
+			if t, ok := a.(*exp.Tupl); ok {
+				fv.Set(reflect.ValueOf(t.Els))
+			}
+		default:
+			arg, err := p.Eval(c.Env, a)
+			if err != nil {
+				return nil, err
+			}
+			if arg == nil || arg.Zero() {
+				continue
+			}
+			val, err := lit.Conv(p.Reg, fv.Type(), arg)
+			if err != nil {
+				return nil, err
+			}
+			fv.Set(val)
+		}
+	}
+	return rv.Interface().(Evaler).Eval(p)
+}
+
+// fieldKey returns the parameter name for field f and whether f is a parameter.
+func fieldKey(f reflect.StructField) (string, bool) {
+	key, opt := f.Tag.Get("json"), ""
+	if idx := strings.IndexByte(key, ','); idx >= 0 {
+		if strings.Contains(key[idx:], ",omitempty") {
+			opt = "?"
+		}
+		key = key[:idx]
+	}
+	if key == "-" {
+		return "", false
+	}
+	if key == "" {
+		key = cor.Keyed(f.Name)
+	}
+	return key + opt, true
+}
+
+var (
+	refEvaler = reflect.TypeOf((*Evaler)(nil)).Elem()
+	refEnv    = reflect.TypeOf((*exp.Env)(nil)).Elem()
+	refExp    = reflect.TypeOf((*exp.Exp)(nil)).Elem()
+	refExps   = reflect.TypeOf([]exp.Exp(nil))
+	refCall   = reflect.TypeOf((*exp.Call)(nil))
+)
@@ -0,0 +1,86 @@
+package ext
+
+import (
+	"strings"
+	"testing"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+)
+
+type joinForm struct {
+	Env  exp.Env
+	Sep  string    `json:"sep"`
+	Els  []exp.Exp `json:"els"`
+	Pref string    `json:"-"`
+}
+
+func (f *joinForm) Eval(p *exp.Prog) (lit.Val, error) {
+	strs := make([]string, 0, len(f.Els))
+	for _, el := range f.Els {
+		v, err := p.Eval(f.Env, el)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, f.Pref+v.String())
+	}
+	return lit.Str(strings.Join(strs, f.Sep)), nil
+}
+
+type orForm struct {
+	Call *exp.Call
+	Val  exp.Exp
+	Else exp.Exp `json:",omitempty"`
+}
+
+func (f *orForm) Eval(p *exp.Prog) (lit.Val, error) {
+	v, err := p.Eval(f.Call.Env, f.Val)
+	if err != nil || !v.Zero() || f.Else == nil {
+		return v, err
+	}
+	return p.Eval(f.Call.Env, f.Else)
+}
+
+func TestForm(t *testing.T) {
+	reg := &lit.PrxReg{}
+	join, err := NewForm(reg, "join", joinForm{Pref: "-"})
+	if err != nil {
+		t.Fatalf("new form join: %v", err)
+	}
+	or, err := NewForm(reg, "or", &orForm{})
+	if err != nil {
+		t.Fatalf("new form or: %v", err)
+	}
+	if got, want := join.Decl.String(), "<form@join sep:str els:tupl|exp any>"; got != want {
+		t.Errorf("join decl want %s got %s", want, got)
+	}
+	if got, want := or.Decl.String(), "<form@or val:exp else?:exp any>"; got != want {
+		t.Errorf("or decl want %s got %s", want, got)
+	}
+	if _, err := NewForm(reg, "bad", struct{ A int }{}); err == nil {
+		t.Errorf("want error for struct without eval method")
+	}
+	env := exp.Builtins(make(lib.Specs).AddMap(lib.Core).Add(join).Add(or))
+	tests := []struct {
+		raw string
+		xlf string
+	}{
+		{`(join '' 'a' 'b')`, `'-a-b'`},
+		{`(join ',' 1 2 3)`, `'-1,-2,-3'`},
+		{`(or 'a' 'b')`, `'a'`},
+		{`(or '' 'b')`, `'b'`},
+		{`(or 0)`, `0`},
+	}
+	for _, test := range tests {
+		got, err := exp.NewProg(env, reg).RunStr(test.raw, nil)
+		if err != nil {
+			t.Errorf("eval %s failed: %v", test.raw, err)
+			continue
+		}
+		if vs := bfr.String(got); vs != test.xlf {
+			t.Errorf("eval %s want %s got %s", test.raw, test.xlf, vs)
+		}
+	}
+}
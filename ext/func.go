@@ -2,8 +2,10 @@
 package ext
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"xelf.org/xelf/exp"
 	"xelf.org/xelf/knd"
@@ -14,16 +16,43 @@ import (
 // Func is func spec implementation calling an native go function using reflection.
 type Func struct {
 	exp.SpecBase
-	val  reflect.Value
-	rts  []reflect.Type
-	vari bool
-	err  bool
+	// Params holds the names, defaults and docs of all xelf parameters.
+	Params []Param
+	val    reflect.Value
+	pre    []reflect.Type
+	rts    []reflect.Type
+	vari   bool
+	err    bool
+}
+
+// Param holds additional information for a parameter of a reflected go function.
+type Param struct {
+	// Name is the parameter name. Optional parameter names end in a question mark.
+	Name string
+	// Def is an optional default value used for missing arguments of optional parameters.
+	Def lit.Val
+	// Doc is an optional parameter documentation.
+	Doc string
 }
 
 // NewFunc reflects function value val and returns a named func spec or an error.
 // The variadic names parameter can be specified for parameter names that is elided from the
 // reflect function type information.
 func NewFunc(reg lit.Reg, name string, val interface{}, names ...string) (*Func, error) {
+	ps := make([]Param, 0, len(names))
+	for _, n := range names {
+		ps = append(ps, Param{Name: n})
+	}
+	return NewFuncParams(reg, name, val, ps...)
+}
+
+// NewFuncParams reflects function value val and returns a named func spec or an error.
+// The leading parameters of type context.Context, *exp.Prog and exp.Env are not part of the
+// signature and provided with the program context, program and call environment.
+// The variadic params describe the remaining function parameters in order. Parameters with a
+// default value are optional and can only be followed by optional or variadic parameters.
+// Missing optional arguments use the default or zero value.
+func NewFuncParams(reg lit.Reg, name string, val interface{}, ps ...Param) (*Func, error) {
 	v := reflect.ValueOf(val)
 	if v.Kind() != reflect.Func {
 		return nil, fmt.Errorf("expect function argument got %T", val)
@@ -37,18 +66,44 @@ func NewFunc(reg lit.Reg, name string, val interface{}, names ...string) (*Func,
 	s := &Func{SpecBase: exp.SpecBase{Decl: typ.Type{Kind: knd.Func, Ref: name, Body: pb}},
 		val: v, rts: make([]reflect.Type, 0, n), vari: t.IsVariadic(),
 	}
-	for i := 0; i < n; i++ {
+	i := 0
+	for ; i < n; i++ {
+		rt := t.In(i)
+		if rt != refCtx && rt != refProg && rt != refEnv {
+			break
+		}
+		s.pre = append(s.pre, rt)
+	}
+	if len(ps) > n-i {
+		return nil, fmt.Errorf("expect at most %d parameter names got %d", n-i, len(ps))
+	}
+	var def string
+	for ; i < n; i++ {
 		rt := t.In(i)
 		lt, err := reg.Reflect(rt)
 		if err != nil {
 			return nil, err
 		}
-		var name string
-		if i < len(names) {
-			name = names[i]
+		var p Param
+		if idx := len(s.rts); idx < len(ps) {
+			p = ps[idx]
+		}
+		if p.Def != nil {
+			if p.Name == "" {
+				return nil, fmt.Errorf("parameter %d with default must be named", len(s.rts))
+			}
+			if !strings.HasSuffix(p.Name, "?") {
+				p.Name += "?"
+			}
+			def = p.Name
+		} else if def != "" && !strings.HasSuffix(p.Name, "?") && !(s.vari && i == n-1) {
+			// a default is useless if it must be followed by a required argument
+			return nil, fmt.Errorf("required parameter %d follows parameter %s with default",
+				len(s.rts), def)
 		}
 		s.rts = append(s.rts, rt)
-		pb.Params = append(pb.Params, typ.P(name, lt))
+		s.Params = append(s.Params, p)
+		pb.Params = append(pb.Params, typ.P(p.Name, lt))
 	}
 	n = t.NumOut()
 	var res typ.Type
@@ -74,26 +129,52 @@ func NewFunc(reg lit.Reg, name string, val interface{}, names ...string) (*Func,
 	return s, nil
 }
 
+// Doc returns the parameter documentation or nil if no parameter is documented.
+func (s *Func) Doc() *exp.Doc {
+	var d *exp.Doc
+	for _, p := range s.Params {
+		if p.Doc != "" {
+			if d == nil {
+				d = &exp.Doc{}
+			}
+			d.Params = append(d.Params, exp.ParamDoc{Name: p.Name, Doc: p.Doc})
+		}
+	}
+	return d
+}
+
 func (s *Func) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
 		return nil, err
 	}
-	// get reflect values from argument
-	rvs := make([]reflect.Value, len(s.rts))
+	// get reflect values from program context and arguments
+	rvs := make([]reflect.Value, 0, len(s.pre)+len(s.rts))
+	for _, rt := range s.pre {
+		switch rt {
+		case refCtx:
+			rvs = append(rvs, reflect.ValueOf(&p.Ctx).Elem())
+		case refProg:
+			rvs = append(rvs, reflect.ValueOf(p))
+		case refEnv:
+			rvs = append(rvs, reflect.ValueOf(&c.Env).Elem())
+		}
+	}
 	for i, rt := range s.rts {
 		arg := args[i]
+		if arg == nil {
+			arg = s.Params[i].Def
+		}
 		if arg == nil || arg.Zero() {
-			ptr := reflect.New(rt)
-			rvs[i] = ptr.Elem()
 			// reflect already provides a zero value
+			rvs = append(rvs, reflect.New(rt).Elem())
 			continue
 		}
 		val, err := lit.Conv(p.Reg, rt, arg)
 		if err != nil {
 			return nil, err
 		}
-		rvs[i] = val
+		rvs = append(rvs, val)
 	}
 	// call reflect function with value
 	var res []reflect.Value
@@ -126,4 +207,8 @@ func (s *Func) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	return lit.Wrap(prx, exp.SigRes(c.Sig).Type), nil
 }
 
-var refErr = reflect.TypeOf((*error)(nil)).Elem()
+var (
+	refErr  = reflect.TypeOf((*error)(nil)).Elem()
+	refCtx  = reflect.TypeOf((*context.Context)(nil)).Elem()
+	refProg = reflect.TypeOf((*exp.Prog)(nil))
+)
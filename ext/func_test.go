@@ -1,6 +1,7 @@
 package ext
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -117,3 +118,55 @@ func TestFuncEval(t *testing.T) {
 		}
 	}
 }
+
+type ctxKey struct{}
+
+func TestFuncParams(t *testing.T) {
+	c := &lit.PrxReg{}
+	rep, err := NewFuncParams(c, "rep", func(ctx context.Context, p *exp.Prog, s string, n int) string {
+		if p == nil {
+			return "no prog"
+		}
+		pre, _ := ctx.Value(ctxKey{}).(string)
+		return pre + strings.Repeat(s, n)
+	}, Param{Name: "s"}, Param{Name: "n", Def: lit.Int(2), Doc: "repeat count"})
+	if err != nil {
+		t.Fatalf("reflect rep: %v", err)
+	}
+	if got, want := rep.Decl.String(), "<func@rep s:str n?:int str>"; got != want {
+		t.Errorf("rep decl want %s got %s", want, got)
+	}
+	if got := rep.Params[1].Doc; got != "repeat count" {
+		t.Errorf("rep param doc got %q", got)
+	}
+	_, err = NewFuncParams(c, "bad", func(n int, s string) string { return s },
+		Param{Name: "n", Def: lit.Int(1)}, Param{Name: "s"})
+	if err == nil {
+		t.Errorf("want error for required param after param with default")
+	}
+	_, err = NewFuncParams(c, "vari", func(n int, s ...string) int { return n },
+		Param{Name: "n", Def: lit.Int(1)}, Param{Name: "s"})
+	if err != nil {
+		t.Errorf("want variadic param after param with default got %v", err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "> ")
+	env := exp.Builtins(lib.Specs{"rep": rep}.AddMap(lib.Core))
+	tests := []struct {
+		raw string
+		xlf string
+	}{
+		{`(rep 'a')`, `'> aa'`},
+		{`(rep 'a' 3)`, `'> aaa'`},
+		{`(rep n:0 s:'a')`, `'> '`},
+	}
+	for _, test := range tests {
+		got, err := exp.NewProg(env, c, ctx).RunStr(test.raw, nil)
+		if err != nil {
+			t.Errorf("eval %s failed: %v", test.raw, err)
+			continue
+		}
+		if vs := bfr.String(got); vs != test.xlf {
+			t.Errorf("eval %s want %s got %s", test.raw, test.xlf, vs)
+		}
+	}
+}
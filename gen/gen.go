@@ -0,0 +1,187 @@
+// Package gen provides a code generation context and helpers to collect xelf type declarations.
+package gen
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/typ"
+)
+
+// Gen is the code generation context used by the language specific generators.
+type Gen struct {
+	bfr.P
+	// Pkg is the package name or path of the generated output.
+	Pkg string
+	// Mod is the name of the module that is generated into this package.
+	Mod string
+	// Pkgs maps other module names to package paths used for qualified type references.
+	Pkgs map[string]string
+	// Header is written at the start of the generated output, usually a code comment.
+	Header string
+	// Imports collects the imports used by the generated output.
+	Imports Imports
+}
+
+// New returns a new generator context for the given module and package.
+func New(mod, pkg string) *Gen {
+	return &Gen{Pkg: pkg, Mod: mod, Pkgs: make(map[string]string)}
+}
+
+// Local returns whether the reference qualifier q refers to the generated module itself.
+func (g *Gen) Local(q string) bool { return q == "" || q == g.Mod }
+
+// Qualify adds the package path for module q to the imports and returns its qualifier or an error.
+func (g *Gen) Qualify(q string) (string, error) {
+	path := g.Pkgs[q]
+	if path == "" {
+		return "", fmt.Errorf("no package path for module %q", q)
+	}
+	return g.Imports.Add(path), nil
+}
+
+// Imports is a set of import paths with their import qualifier.
+type Imports map[string]string
+
+// Add adds path to the imports and returns the qualifier.
+func (is *Imports) Add(path string) string {
+	if *is == nil {
+		*is = make(Imports)
+	}
+	q := (*is)[path]
+	if q == "" {
+		q = path
+		if idx := strings.LastIndexByte(q, '/'); idx >= 0 {
+			q = q[idx+1:]
+		}
+		(*is)[path] = q
+	}
+	return q
+}
+
+// List returns a sorted list of all import paths.
+func (is Imports) List() []string {
+	res := make([]string, 0, len(is))
+	for path := range is {
+		res = append(res, path)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ReadMods reads and evaluates the xelf file at path in env and returns all modules declared in
+// that file. The env should usually be a mod.LoaderEnv to support imports.
+func ReadMods(env exp.Env, path string) ([]*exp.Mod, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	x, err := exp.Read(f, path)
+	if err != nil {
+		return nil, err
+	}
+	p := exp.NewProg(env)
+	p.File.URL = path
+	_, err = p.Run(x, nil)
+	if err != nil {
+		return nil, err
+	}
+	var res []*exp.Mod
+	for _, ref := range p.File.Refs {
+		if ref.Pub && ref.Mod != nil && ref.File == &p.File {
+			res = append(res, ref.Mod)
+		}
+	}
+	return res, nil
+}
+
+// Types returns all type declarations of module m in declaration order.
+// Every returned type and all unqualified type references within are qualified with the module
+// name, so that the types match the types reflected from generated code.
+func Types(m *exp.Mod) ([]typ.Type, error) {
+	pb, ok := m.Decl.Typ.Body.(*typ.ParamBody)
+	if !ok {
+		return nil, nil
+	}
+	res := make([]typ.Type, 0, len(pb.Params))
+	for i, p := range pb.Params {
+		if p.Kind&knd.Typ == 0 {
+			continue
+		}
+		t, err := typ.ToType(m.Decl.Vals[i])
+		if err != nil {
+			return nil, err
+		}
+		if t.Ref == "" {
+			t.Ref = p.Name
+		}
+		t, err = typ.Edit(selfSels(t, nil), func(e *typ.Editor) (typ.Type, error) {
+			r := e.Type
+			if r.Kind&(knd.Obj|knd.Enum|knd.Bits) != 0 && r.Ref != "" && !strings.Contains(r.Ref, ".") {
+				r.Ref = m.Name + "." + r.Ref
+			}
+			return r, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// selfSels returns a copy of t with recursive obj references replaced by type selections.
+func selfSels(t typ.Type, stack []typ.Body) typ.Type {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if t.Body == stack[i] {
+			s := typ.Sel(strings.Repeat(".", len(stack)-i))
+			s.Kind |= t.Kind & knd.None
+			return s
+		}
+	}
+	switch b := t.Body.(type) {
+	case *typ.Type:
+		el := selfSels(*b, stack)
+		t.Body = &el
+	case *typ.ParamBody:
+		if t.Kind&knd.Obj != 0 {
+			stack = append(stack, b)
+		}
+		ps := make([]typ.Param, len(b.Params))
+		for i, p := range b.Params {
+			p.Type = selfSels(p.Type, stack)
+			ps[i] = p
+		}
+		t.Body = &typ.ParamBody{Params: ps}
+	}
+	return t
+}
+
+// RefName splits a qualified type reference into module qualifier and cased type name.
+func RefName(ref string) (q, name string) {
+	q, name = exp.SplitQualifier(ref)
+	return q, cor.Cased(strings.TrimPrefix(name, "."))
+}
+
+// Consts returns the constants of an enum or bits type or nil.
+func Consts(t typ.Type) []typ.Const {
+	if cb, ok := t.Body.(*typ.ConstBody); ok {
+		return cb.Consts
+	}
+	return nil
+}
+
+// Params returns the params of an obj or spec type or nil.
+func Params(t typ.Type) []typ.Param {
+	if pb, ok := t.Body.(*typ.ParamBody); ok {
+		return pb.Params
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+// Package gengo generates go code from xelf type declarations.
+//
+// Obj types are generated as structs with json tags, enum types as string types and bits types as
+// uint64 types with typed constants, a String method and a parse function. The generated enum and
+// bits types implement lit.Consts, so that all generated types reflect to the declared types.
+package gengo
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/gen"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/typ"
+)
+
+// Header is the default header for generated go files.
+const Header = "// Code generated by xelfgen. DO NOT EDIT."
+
+const (
+	pkgTyp = "xelf.org/xelf/typ"
+	pkgLit = "xelf.org/xelf/lit"
+)
+
+// WriteFile writes a formatted go file with declarations for all types ts to w or returns an error.
+func WriteFile(g *gen.Gen, w io.Writer, ts []typ.Type) error {
+	var body strings.Builder
+	g.P = bfr.P{Writer: &body, Plain: true}
+	for _, t := range ts {
+		if err := WriteDecl(g, t); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	header := g.Header
+	if header == "" {
+		header = Header
+	}
+	fmt.Fprintf(&b, "%s\n\npackage %s\n", header, pkgName(g.Pkg))
+	if imps := g.Imports.List(); len(imps) > 0 {
+		b.WriteString("\nimport (\n")
+		var std bool
+		for i, imp := range imps {
+			// std packages sort before other packages
+			if i == 0 {
+				std = !strings.Contains(imp, ".")
+			} else if std && strings.Contains(imp, ".") {
+				std = false
+				b.WriteByte('\n')
+			}
+			fmt.Fprintf(&b, "\t%q\n", imp)
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString(body.String())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// WriteDecl writes a go declaration for the named type t or returns an error.
+func WriteDecl(g *gen.Gen, t typ.Type) error {
+	_, name := gen.RefName(t.Ref)
+	if name == "" {
+		return fmt.Errorf("cannot declare unnamed type %s", t)
+	}
+	g.Byte('\n')
+	switch t.Kind & knd.All {
+	case knd.Obj:
+		fs, err := fields(g, gen.Params(t), []string{name})
+		if err != nil {
+			return fmt.Errorf("declare %s: %v", name, err)
+		}
+		return g.Fmt("type %s %s\n", name, fs)
+	case knd.Enum:
+		return writeEnum(g, name, gen.Consts(t))
+	case knd.Bits:
+		return writeBits(g, name, gen.Consts(t))
+	}
+	tt, err := GoType(g, t)
+	if err != nil {
+		return fmt.Errorf("declare %s: %v", name, err)
+	}
+	return g.Fmt("type %s %s\n", name, tt)
+}
+
+// GoType returns the go type expression for t or an error. It adds required imports to g.
+func GoType(g *gen.Gen, t typ.Type) (string, error) { return goType(g, t, nil) }
+
+func goType(g *gen.Gen, t typ.Type, stack []string) (res string, err error) {
+	if t.Kind&knd.Sel != 0 {
+		n := len(t.Ref)
+		if n == 0 || strings.Trim(t.Ref, ".") != "" || n > len(stack) || stack[len(stack)-n] == "" {
+			return "", fmt.Errorf("unsupported type selection %s", t)
+		}
+		res = stack[len(stack)-n]
+	} else {
+		switch k := t.Kind & knd.All; k {
+		case knd.Bool:
+			res = "bool"
+		case knd.Int:
+			res = "int64"
+		case knd.Num, knd.Real:
+			res = "float64"
+		case knd.Char, knd.Str:
+			res = "string"
+		case knd.Raw:
+			res = "[]byte"
+		case knd.UUID:
+			res = "[16]byte"
+		case knd.Time:
+			res = g.Imports.Add("time") + ".Time"
+		case knd.Span:
+			res = g.Imports.Add("time") + ".Duration"
+		case knd.Typ:
+			res = g.Imports.Add(pkgTyp) + ".Type"
+		case knd.Enum, knd.Bits:
+			if t.Ref != "" {
+				res, err = refType(g, t.Ref)
+			} else if k == knd.Enum {
+				res = "string"
+			} else {
+				res = "uint64"
+			}
+		case knd.Obj:
+			if t.Ref != "" {
+				res, err = refType(g, t.Ref)
+			} else {
+				res, err = fields(g, gen.Params(t), append(stack, ""))
+			}
+		case knd.List:
+			if el := typ.El(t); el == typ.Void {
+				res = g.Imports.Add(pkgLit) + ".List"
+			} else if res, err = goType(g, el, stack); err == nil {
+				res = "[]" + res
+			}
+		case knd.Dict:
+			if el := typ.El(t); el == typ.Void {
+				res = g.Imports.Add(pkgLit) + ".Dict"
+			} else if res, err = goType(g, el, stack); err == nil {
+				res = "map[string]" + res
+			}
+		default:
+			return "interface{}", nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if t.Kind&knd.None != 0 {
+		res = "*" + res
+	}
+	return res, nil
+}
+
+func refType(g *gen.Gen, ref string) (string, error) {
+	q, name := gen.RefName(ref)
+	if g.Local(q) {
+		return name, nil
+	}
+	pkg, err := g.Qualify(q)
+	if err != nil {
+		return "", err
+	}
+	return pkg + "." + name, nil
+}
+
+func fields(g *gen.Gen, ps []typ.Param, stack []string) (string, error) {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, p := range ps {
+		if p.Name == "" {
+			return "", fmt.Errorf("unsupported unnamed field %s", p.Type)
+		}
+		ft, err := goType(g, p.Type, stack)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %v", p.Name, err)
+		}
+		// json tags use the param key like lit does for obj values
+		tag := p.Key
+		if p.IsOpt() {
+			if !omitEffect(p.Type, ft) {
+				// optional struct and array values need a pointer to be omitted
+				ft = "*" + ft
+			}
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", FieldName(p.Name), ft, tag)
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+// omitEffect returns whether the json omitempty option has any effect on go type ft for t.
+// Omitempty is ignored for struct and array values.
+func omitEffect(t typ.Type, ft string) bool {
+	if strings.HasPrefix(ft, "*") {
+		return true
+	}
+	switch t.Kind & knd.All {
+	case knd.Obj, knd.UUID, knd.Time, knd.Typ:
+		return false
+	}
+	return true
+}
+
+// initialisms are written in upper case in go names.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// FieldName returns the exported go name for the param name. Words that are initialisms
+// like ID, URL or UUID are written in upper case.
+func FieldName(name string) string {
+	name = cor.Cased(strings.TrimSuffix(name, "?"))
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(name); i++ {
+		if i < len(name) && !(name[i] >= 'A' && name[i] <= 'Z' && name[i-1] >= 'a' && name[i-1] <= 'z') {
+			continue
+		}
+		w := name[start:i]
+		if up := strings.ToUpper(w); initialisms[up] {
+			w = up
+		}
+		b.WriteString(w)
+		start = i
+	}
+	return b.String()
+}
+
+func writeEnum(g *gen.Gen, name string, cs []typ.Const) error {
+	g.Imports.Add("fmt")
+	g.Fmt("type %s string\n\nconst (\n", name)
+	for _, c := range cs {
+		g.Fmt("\t%s%s %s = %q\n", name, cor.Cased(c.Name), name, c.Key)
+	}
+	g.Fmt(")\n\n")
+	writeConsts(g, name, cs)
+	g.Fmt("func (v %s) String() string { return string(v) }\n\n", name)
+	g.Fmt("// Parse%s returns the %[1]s constant for str or an error.\n", name)
+	g.Fmt("func Parse%s(str string) (%[1]s, error) {\n", name)
+	g.Fmt("\tkey := %s.ToLower(str)\n", g.Imports.Add("strings"))
+	g.Fmt("\tfor _, c := range %s {\n", constsVar(name))
+	g.Fmt("\t\tif c.Key == key {\n\t\t\treturn %s(c.Key), nil\n\t\t}\n\t}\n", name)
+	return g.Fmt("\treturn \"\", fmt.Errorf(\"invalid %s %%q\", str)\n}\n", name)
+}
+
+func writeBits(g *gen.Gen, name string, cs []typ.Const) error {
+	strs := g.Imports.Add("strings")
+	g.Imports.Add("fmt")
+	g.Fmt("type %s uint64\n\nconst (\n", name)
+	for _, c := range cs {
+		if c.Val < 0 {
+			return fmt.Errorf("declare %s: bits constant %s needs a value", name, c.Name)
+		}
+		g.Fmt("\t%s%s %s = %d\n", name, cor.Cased(c.Name), name, c.Val)
+	}
+	g.Fmt(")\n\n")
+	writeConsts(g, name, cs)
+	cv := constsVar(name)
+	g.Fmt("func (v %s) String() string {\n\tvar res []string\n", name)
+	g.Fmt("\tfor _, c := range %s {\n", cv)
+	g.Fmt("\t\tif b := %s(c.Val); b != 0 && v&b == b {\n", name)
+	g.Fmt("\t\t\tres = append(res, c.Key)\n\t\t\tv &^= b\n\t\t}\n\t}\n")
+	g.Fmt("\tif v != 0 {\n\t\tres = append(res, fmt.Sprint(uint64(v)))\n\t}\n")
+	g.Fmt("\treturn %s.Join(res, \"|\")\n}\n\n", strs)
+	g.Fmt("// Parse%s returns the %[1]s value for the bit names in str separated by '|' or an error.\n", name)
+	g.Fmt("func Parse%s(str string) (res %[1]s, _ error) {\n", name)
+	g.Fmt("Parts:\n\tfor _, s := range %s.Split(str, \"|\") {\n", strs)
+	g.Fmt("\t\tkey := %[1]s.ToLower(%[1]s.TrimSpace(s))\n", strs)
+	g.Fmt("\t\tif key == \"\" {\n\t\t\tcontinue\n\t\t}\n")
+	g.Fmt("\t\tfor _, c := range %s {\n", cv)
+	g.Fmt("\t\t\tif c.Key == key {\n\t\t\t\tres |= %s(c.Val)\n\t\t\t\tcontinue Parts\n\t\t\t}\n\t\t}\n", name)
+	g.Fmt("\t\treturn 0, fmt.Errorf(\"invalid %s %%q\", s)\n\t}\n", name)
+	return g.Fmt("\treturn res, nil\n}\n")
+}
+
+func writeConsts(g *gen.Gen, name string, cs []typ.Const) {
+	t := g.Imports.Add(pkgTyp)
+	cv := constsVar(name)
+	g.Fmt("var %s = []%s.Const{\n", cv, t)
+	for _, c := range cs {
+		g.Fmt("\t%s.C(%q, %d),\n", t, c.Name, c.Val)
+	}
+	g.Fmt("}\n\n")
+	g.Fmt("// Consts returns the xelf constants of %s.\n", name)
+	g.Fmt("func (%s) Consts() []%s.Const { return %s }\n\n", name, t, cv)
+}
+
+func constsVar(name string) string {
+	return strings.ToLower(name[:1]) + name[1:] + "Consts"
+}
+
+func pkgName(pkg string) string {
+	if idx := strings.LastIndexByte(pkg, '/'); idx >= 0 {
+		pkg = pkg[idx+1:]
+	}
+	return pkg
+}
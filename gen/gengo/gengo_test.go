@@ -0,0 +1,146 @@
+package gengo
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/gen"
+	"xelf.org/xelf/gen/gengo/internal/prod"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/mod"
+	"xelf.org/xelf/typ"
+)
+
+func prodTypes(t *testing.T) []typ.Type {
+	env := mod.NewLoaderEnv(exp.Builtins(lib.Std), mod.FileMods())
+	ms, err := gen.ReadMods(env, "../testdata/prod.xelf")
+	if err != nil {
+		t.Fatalf("read mods: %v", err)
+	}
+	if len(ms) != 1 {
+		t.Fatalf("want one module got %d", len(ms))
+	}
+	ts, err := gen.Types(ms[0])
+	if err != nil {
+		t.Fatalf("mod types: %v", err)
+	}
+	return ts
+}
+
+func TestWriteFile(t *testing.T) {
+	ts := prodTypes(t)
+	var b bytes.Buffer
+	err := WriteFile(gen.New("prod", "prod"), &b, ts)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	want, err := os.ReadFile("internal/prod/prod.go")
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got := b.String(); got != string(want) {
+		t.Errorf("generated code differs from internal/prod/prod.go got:\n%s", got)
+	}
+}
+
+func TestReflect(t *testing.T) {
+	ts := prodTypes(t)
+	vals := []interface{}{prod.Kind(""), prod.Perm(0), prod.Prod{}, prod.Cat{}}
+	if len(ts) != len(vals) {
+		t.Fatalf("want %d types got %d", len(vals), len(ts))
+	}
+	reg := &lit.PrxReg{}
+	for i, want := range ts {
+		got, err := reg.Reflect(reflect.TypeOf(vals[i]))
+		if err != nil {
+			t.Errorf("reflect %T: %v", vals[i], err)
+			continue
+		}
+		// generated fields use the param keys and pointers for optional struct values
+		want, got = keyedFields(want), keyedFields(got)
+		if !got.Equal(want) {
+			want.Ref, got.Ref = "", ""
+			t.Errorf("reflect %T want %s got %s", vals[i], want, got)
+		}
+	}
+}
+
+func keyedFields(t typ.Type) typ.Type {
+	res, _ := typ.Edit(t, func(e *typ.Editor) (typ.Type, error) {
+		pb, ok := e.Body.(*typ.ParamBody)
+		if !ok || e.Kind&knd.Obj == 0 {
+			return e.Type, nil
+		}
+		ps := make([]typ.Param, 0, len(pb.Params))
+		for _, p := range pb.Params {
+			if p.IsOpt() {
+				ps = append(ps, typ.P(p.Key+"?", typ.Deopt(p.Type)))
+			} else {
+				ps = append(ps, typ.P(p.Key, p.Type))
+			}
+		}
+		r := e.Type
+		r.Body = &typ.ParamBody{Params: ps}
+		return r, nil
+	})
+	return res
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"<int>", "int64"},
+		{"<int?>", "*int64"},
+		{"<list|str>", "[]string"},
+		{"<dict|list|time>", "map[string][]time.Time"},
+		{"<list>", "lit.List"},
+		{"<obj@prod.Prod?>", "*Prod"},
+		{"<obj@other.Foo>", "other.Foo"},
+		{"<enum@prod.Kind>", "Kind"},
+		{"<any>", "interface{}"},
+	}
+	for _, test := range tests {
+		g := gen.New("prod", "prod")
+		g.Pkgs["other"] = "example.org/other"
+		tt, err := typ.Parse(test.raw)
+		if err != nil {
+			t.Errorf("parse %s: %v", test.raw, err)
+			continue
+		}
+		got, err := GoType(g, tt)
+		if err != nil {
+			t.Errorf("go type %s: %v", test.raw, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("go type %s want %s got %s", test.raw, test.want, got)
+		}
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"name", "Name"},
+		{"id", "ID"},
+		{"ID", "ID"},
+		{"uuid?", "UUID"},
+		{"prodId", "ProdID"},
+		{"apiURL", "APIURL"},
+		{"valid", "Valid"},
+	}
+	for _, test := range tests {
+		if got := FieldName(test.name); got != test.want {
+			t.Errorf("field name %s want %s got %s", test.name, test.want, got)
+		}
+	}
+}
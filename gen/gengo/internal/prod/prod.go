@@ -0,0 +1,110 @@
+// Code generated by xelfgen. DO NOT EDIT.
+
+package prod
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"xelf.org/xelf/typ"
+)
+
+type Kind string
+
+const (
+	KindFood Kind = "food"
+	KindTool Kind = "tool"
+)
+
+var kindConsts = []typ.Const{
+	typ.C("food", -1),
+	typ.C("tool", -1),
+}
+
+// Consts returns the xelf constants of Kind.
+func (Kind) Consts() []typ.Const { return kindConsts }
+
+func (v Kind) String() string { return string(v) }
+
+// ParseKind returns the Kind constant for str or an error.
+func ParseKind(str string) (Kind, error) {
+	key := strings.ToLower(str)
+	for _, c := range kindConsts {
+		if c.Key == key {
+			return Kind(c.Key), nil
+		}
+	}
+	return "", fmt.Errorf("invalid Kind %q", str)
+}
+
+type Perm uint64
+
+const (
+	PermRead  Perm = 1
+	PermWrite Perm = 2
+	PermAdmin Perm = 4
+)
+
+var permConsts = []typ.Const{
+	typ.C("read", 1),
+	typ.C("write", 2),
+	typ.C("admin", 4),
+}
+
+// Consts returns the xelf constants of Perm.
+func (Perm) Consts() []typ.Const { return permConsts }
+
+func (v Perm) String() string {
+	var res []string
+	for _, c := range permConsts {
+		if b := Perm(c.Val); b != 0 && v&b == b {
+			res = append(res, c.Key)
+			v &^= b
+		}
+	}
+	if v != 0 {
+		res = append(res, fmt.Sprint(uint64(v)))
+	}
+	return strings.Join(res, "|")
+}
+
+// ParsePerm returns the Perm value for the bit names in str separated by '|' or an error.
+func ParsePerm(str string) (res Perm, _ error) {
+Parts:
+	for _, s := range strings.Split(str, "|") {
+		key := strings.ToLower(strings.TrimSpace(s))
+		if key == "" {
+			continue
+		}
+		for _, c := range permConsts {
+			if c.Key == key {
+				res |= Perm(c.Val)
+				continue Parts
+			}
+		}
+		return 0, fmt.Errorf("invalid Perm %q", s)
+	}
+	return res, nil
+}
+
+type Prod struct {
+	ID      int64              `json:"id"`
+	Name    string             `json:"name"`
+	Kind    Kind               `json:"kind"`
+	Perm    Perm               `json:"perm,omitempty"`
+	Tags    []string           `json:"tags,omitempty"`
+	Created time.Time          `json:"created"`
+	Dur     *time.Duration     `json:"dur,omitempty"`
+	UUID    *[16]byte          `json:"uuid,omitempty"`
+	Data    map[string]float64 `json:"data"`
+	Parts   []Prod             `json:"parts,omitempty"`
+	Info    *struct {
+		Note string `json:"note"`
+	} `json:"info,omitempty"`
+}
+
+type Cat struct {
+	Name  string `json:"name"`
+	Prods []Prod `json:"prods"`
+}
@@ -0,0 +1,337 @@
+// Package gents generates typescript code from xelf type declarations.
+//
+// Obj types are generated as interfaces with a decode and encode function, enum types as string
+// literal unions and bits types as number types with a const object of all flags.
+//
+// Values are expected in the json format used by package lit. Times are decoded as Date and spans
+// as number of milliseconds, raw, uuid and typ values are kept as strings. The generated codec
+// functions use the runtime helpers in Runtime, that are imported from the package path of the
+// module 'xelf' in the generation context or './xelf'.
+package gents
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/gen"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/typ"
+)
+
+// Header is the default header for generated typescript files.
+const Header = "// Code generated by xelfgen. DO NOT EDIT."
+
+// Runtime is the source of the typescript runtime helpers used by the generated code.
+//
+//go:embed xelf.ts
+var Runtime string
+
+// WriteFile writes a typescript file with declarations for all types ts to w or returns an error.
+func WriteFile(g *gen.Gen, w io.Writer, ts []typ.Type) error {
+	var body strings.Builder
+	g.P = bfr.P{Writer: &body, Plain: true}
+	for _, t := range ts {
+		if err := WriteDecl(g, t); err != nil {
+			return err
+		}
+	}
+	header := g.Header
+	if header == "" {
+		header = Header
+	}
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteByte('\n')
+	if imps := g.Imports.List(); len(imps) > 0 {
+		b.WriteByte('\n')
+		for _, imp := range imps {
+			fmt.Fprintf(&b, "import * as %s from '%s'\n", g.Imports[imp], imp)
+		}
+	}
+	b.WriteString(body.String())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDecl writes a typescript declaration for the named type t or returns an error.
+// Obj declarations are followed by a decode and encode function.
+func WriteDecl(g *gen.Gen, t typ.Type) error {
+	_, name := gen.RefName(t.Ref)
+	if name == "" {
+		return fmt.Errorf("cannot declare unnamed type %s", t)
+	}
+	g.Byte('\n')
+	switch t.Kind & knd.All {
+	case knd.Obj:
+		stack := []string{name}
+		ps := gen.Params(t)
+		fs, err := fields(g, ps, stack, "\n")
+		if err != nil {
+			return fmt.Errorf("declare %s: %v", name, err)
+		}
+		g.Fmt("export interface %s %s\n", name, fs)
+		for _, dir := range []string{"decode", "encode"} {
+			fs, err := codecFields(g, ps, stack, dir, "\n\t\t")
+			if err != nil {
+				return fmt.Errorf("declare %s: %v", name, err)
+			}
+			in, out := "any", name
+			if dir == "encode" {
+				in, out = out, in
+			}
+			g.Fmt("\nexport function %s%s(v: %s): %s {\n", dir, name, in, out)
+			g.Fmt("\treturn %s\n}\n", fs)
+		}
+		return nil
+	case knd.Enum:
+		cs := gen.Consts(t)
+		keys := make([]string, 0, len(cs))
+		for _, c := range cs {
+			keys = append(keys, fmt.Sprintf("'%s'", c.Key))
+		}
+		g.Fmt("export type %s = %s\n", name, strings.Join(keys, " | "))
+		return g.Fmt("export const %sKeys: %[2]s[] = [%s]\n", lowerFirst(name), name, strings.Join(keys, ", "))
+	case knd.Bits:
+		g.Fmt("export type %s = number\n", name)
+		g.Fmt("export const %s = {\n", name)
+		for _, c := range gen.Consts(t) {
+			if c.Val < 0 {
+				return fmt.Errorf("declare %s: bits constant %s needs a value", name, c.Name)
+			}
+			g.Fmt("\t%s: %d,\n", propName(c.Key), c.Val)
+		}
+		return g.Fmt("} as const\n")
+	}
+	tt, err := TSType(g, t)
+	if err != nil {
+		return fmt.Errorf("declare %s: %v", name, err)
+	}
+	return g.Fmt("export type %s = %s\n", name, tt)
+}
+
+// TSType returns the typescript type expression for t or an error. It adds required imports to g.
+func TSType(g *gen.Gen, t typ.Type) (string, error) { return tsType(g, t, nil) }
+
+func tsType(g *gen.Gen, t typ.Type, stack []string) (res string, err error) {
+	if t.Kind&knd.Sel != 0 {
+		res, err = selName(t, stack)
+	} else {
+		switch k := t.Kind & knd.All; k {
+		case knd.Bool:
+			res = "boolean"
+		case knd.Int, knd.Num, knd.Real, knd.Span:
+			res = "number"
+		case knd.Char, knd.Str, knd.Raw, knd.UUID, knd.Typ:
+			res = "string"
+		case knd.Time:
+			res = "Date"
+		case knd.Enum, knd.Bits:
+			if t.Ref != "" {
+				res, err = refName(g, t.Ref, "")
+			} else if k == knd.Enum {
+				res = "string"
+			} else {
+				res = "number"
+			}
+		case knd.Obj:
+			if t.Ref != "" {
+				res, err = refName(g, t.Ref, "")
+			} else {
+				res, err = fields(g, gen.Params(t), append(stack, ""), " ")
+			}
+		case knd.List:
+			if el := typ.El(t); el == typ.Void {
+				res = "any[]"
+			} else if res, err = tsType(g, el, stack); err == nil {
+				if strings.ContainsAny(res, " |") {
+					res = "(" + res + ")"
+				}
+				res += "[]"
+			}
+		case knd.Dict:
+			if el := typ.El(t); el == typ.Void {
+				res = "Record<string, any>"
+			} else if res, err = tsType(g, el, stack); err == nil {
+				res = "Record<string, " + res + ">"
+			}
+		default:
+			return "any", nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if t.Kind&knd.None != 0 {
+		res += " | null"
+	}
+	return res, nil
+}
+
+func fields(g *gen.Gen, ps []typ.Param, stack []string, sep string) (string, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	for _, p := range ps {
+		if p.Key == "" {
+			return "", fmt.Errorf("unsupported unnamed field %s", p.Type)
+		}
+		ft, err := tsType(g, p.Type, stack)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %v", p.Name, err)
+		}
+		opt := ""
+		if p.IsOpt() {
+			opt = "?"
+		}
+		if sep == "\n" {
+			b.WriteString("\n\t")
+		} else {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "%s%s: %s", propName(p.Key), opt, ft)
+		if sep != "\n" {
+			b.WriteByte(';')
+		}
+	}
+	if len(ps) > 0 {
+		b.WriteString(sep)
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+// Codec returns a typescript function expression that decodes or encodes values of type t
+// depending on dir, or an empty string if the json value can be used as is.
+// Dir must be either "decode" or "encode". Codec adds required imports to g.
+func Codec(g *gen.Gen, t typ.Type, dir string) (string, error) { return codec(g, t, nil, dir) }
+
+func codec(g *gen.Gen, t typ.Type, stack []string, dir string) (res string, err error) {
+	if t.Kind&knd.Sel != 0 {
+		res, err = selName(t, stack)
+		res = dir + res
+	} else {
+		switch t.Kind & knd.All {
+		case knd.Time:
+			res = runtime(g) + "." + dir + "Time"
+		case knd.Span:
+			res = runtime(g) + "." + dir + "Span"
+		case knd.Obj:
+			if t.Ref != "" {
+				res, err = refName(g, t.Ref, dir)
+				break
+			}
+			var fs string
+			fs, err = codecFields(g, gen.Params(t), append(stack, ""), dir, " ")
+			if err == nil && fs != "v" {
+				res = fmt.Sprintf("(v: any) => (%s)", fs)
+			}
+		case knd.List, knd.Dict:
+			el := typ.El(t)
+			if el == typ.Void {
+				break
+			}
+			if res, err = codec(g, el, stack, dir); err == nil && res != "" {
+				fn := "list"
+				if t.Kind&knd.Dict != 0 {
+					fn = "dict"
+				}
+				res = fmt.Sprintf("%s.%s(%s)", runtime(g), fn, res)
+			}
+		}
+	}
+	if err != nil || res == "" {
+		return "", err
+	}
+	if t.Kind&knd.None != 0 {
+		res = fmt.Sprintf("%s.nullable(%s)", runtime(g), res)
+	}
+	return res, nil
+}
+
+// codecFields returns an object expression that converts all fields of v that need a codec or
+// just v if no field needs conversion.
+func codecFields(g *gen.Gen, ps []typ.Param, stack []string, dir, sep string) (string, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	b.WriteString(sep)
+	b.WriteString("...v,")
+	var n int
+	for _, p := range ps {
+		f, err := codec(g, p.Type, stack, dir)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %v", p.Name, err)
+		}
+		if f == "" {
+			continue
+		}
+		if p.IsOpt() {
+			f = fmt.Sprintf("%s.opt(%s)", runtime(g), f)
+		}
+		n++
+		b.WriteString(sep)
+		fmt.Fprintf(&b, "%s: %s(v%s),", propName(p.Key), f, propAccess(p.Key))
+	}
+	if n == 0 {
+		return "v", nil
+	}
+	b.WriteString(strings.TrimSuffix(sep, "\t"))
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+func selName(t typ.Type, stack []string) (string, error) {
+	n := len(t.Ref)
+	if n == 0 || strings.Trim(t.Ref, ".") != "" || n > len(stack) || stack[len(stack)-n] == "" {
+		return "", fmt.Errorf("unsupported type selection %s", t)
+	}
+	return stack[len(stack)-n], nil
+}
+
+func refName(g *gen.Gen, ref, prefix string) (string, error) {
+	q, name := gen.RefName(ref)
+	if g.Local(q) {
+		return prefix + name, nil
+	}
+	pkg, err := g.Qualify(q)
+	if err != nil {
+		return "", err
+	}
+	return pkg + "." + prefix + name, nil
+}
+
+func runtime(g *gen.Gen) string {
+	if path := g.Pkgs["xelf"]; path != "" {
+		return g.Imports.Add(path)
+	}
+	return g.Imports.Add("./xelf")
+}
+
+func propName(key string) string {
+	if isIdent(key) {
+		return key
+	}
+	return fmt.Sprintf("'%s'", key)
+}
+
+func propAccess(key string) string {
+	if isIdent(key) {
+		return "." + key
+	}
+	return fmt.Sprintf("['%s']", key)
+}
+
+func isIdent(key string) bool {
+	for i, r := range key {
+		if !cor.NameStart(r) && (i == 0 || !cor.NamePart(r)) {
+			return false
+		}
+	}
+	return key != ""
+}
+
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,84 @@
+package gents
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/gen"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/mod"
+	"xelf.org/xelf/typ"
+)
+
+func TestWriteFile(t *testing.T) {
+	env := mod.NewLoaderEnv(exp.Builtins(lib.Std), mod.FileMods())
+	ms, err := gen.ReadMods(env, "../testdata/prod.xelf")
+	if err != nil {
+		t.Fatalf("read mods: %v", err)
+	}
+	ts, err := gen.Types(ms[0])
+	if err != nil {
+		t.Fatalf("mod types: %v", err)
+	}
+	var b bytes.Buffer
+	err = WriteFile(gen.New("prod", "prod"), &b, ts)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	want, err := os.ReadFile("testdata/prod.ts")
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got := b.String(); got != string(want) {
+		t.Errorf("generated code differs from testdata/prod.ts got:\n%s", got)
+	}
+}
+
+func TestTSType(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  string
+		codec string
+	}{
+		{"<int>", "number", ""},
+		{"<int?>", "number | null", ""},
+		{"<span>", "number", "xelf.decodeSpan"},
+		{"<time?>", "Date | null", "xelf.nullable(xelf.decodeTime)"},
+		{"<list|str?>", "(string | null)[]", ""},
+		{"<dict|list|time>", "Record<string, Date[]>", "xelf.dict(xelf.list(xelf.decodeTime))"},
+		{"<list>", "any[]", ""},
+		{"<obj@prod.Prod?>", "Prod | null", "xelf.nullable(decodeProd)"},
+		{"<obj@other.Foo>", "other.Foo", "other.decodeFoo"},
+		{"<enum@prod.Kind>", "Kind", ""},
+		{"<obj a:int b?:time>", "{ a: number; b?: Date; }",
+			"(v: any) => ({ ...v, b: xelf.opt(xelf.decodeTime)(v.b), })"},
+		{"<any>", "any", ""},
+	}
+	for _, test := range tests {
+		g := gen.New("prod", "prod")
+		g.Pkgs["other"] = "./other"
+		tt, err := typ.Parse(test.raw)
+		if err != nil {
+			t.Errorf("parse %s: %v", test.raw, err)
+			continue
+		}
+		got, err := TSType(g, tt)
+		if err != nil {
+			t.Errorf("ts type %s: %v", test.raw, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ts type %s want %s got %s", test.raw, test.want, got)
+		}
+		got, err = Codec(g, tt, "decode")
+		if err != nil {
+			t.Errorf("codec %s: %v", test.raw, err)
+			continue
+		}
+		if got != test.codec {
+			t.Errorf("codec %s want %s got %s", test.raw, test.codec, got)
+		}
+	}
+}
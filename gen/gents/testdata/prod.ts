@@ -0,0 +1,64 @@
+// Code generated by xelfgen. DO NOT EDIT.
+
+import * as xelf from './xelf'
+
+export type Kind = 'food' | 'tool'
+export const kindKeys: Kind[] = ['food', 'tool']
+
+export type Perm = number
+export const Perm = {
+	read: 1,
+	write: 2,
+	admin: 4,
+} as const
+
+export interface Prod {
+	id: number
+	name: string
+	kind: Kind
+	perm?: Perm
+	tags?: string[]
+	created: Date
+	dur?: number | null
+	uuid?: string
+	data: Record<string, number>
+	parts?: Prod[]
+	info?: { note: string; }
+}
+
+export function decodeProd(v: any): Prod {
+	return {
+		...v,
+		created: xelf.decodeTime(v.created),
+		dur: xelf.opt(xelf.nullable(xelf.decodeSpan))(v.dur),
+		parts: xelf.opt(xelf.list(decodeProd))(v.parts),
+	}
+}
+
+export function encodeProd(v: Prod): any {
+	return {
+		...v,
+		created: xelf.encodeTime(v.created),
+		dur: xelf.opt(xelf.nullable(xelf.encodeSpan))(v.dur),
+		parts: xelf.opt(xelf.list(encodeProd))(v.parts),
+	}
+}
+
+export interface Cat {
+	name: string
+	prods: Prod[]
+}
+
+export function decodeCat(v: any): Cat {
+	return {
+		...v,
+		prods: xelf.list(decodeProd)(v.prods),
+	}
+}
+
+export function encodeCat(v: Cat): any {
+	return {
+		...v,
+		prods: xelf.list(encodeProd)(v.prods),
+	}
+}
@@ -0,0 +1,72 @@
+// Runtime helpers for typescript code generated by xelfgen.
+//
+// The helpers decode and encode the json representation of xelf literals:
+// times are RFC3339 strings and decoded as Date, spans are strings in the format '-1:02:03.456'
+// and decoded as number of milliseconds. All other kinds use plain json values.
+
+export type Codec<T> = (v: any) => T
+
+// opt returns a codec for optional fields that passes undefined values through and calls f otherwise.
+export function opt<T>(f: Codec<T>): Codec<T | undefined> {
+	return (v: any) => (v === undefined ? v : f(v))
+}
+
+// nullable returns a codec for none types that passes null values through and calls f otherwise.
+export function nullable<T>(f: Codec<T>): Codec<T | null> {
+	return (v: any) => (v === null ? v : f(v))
+}
+
+// list returns a codec that calls f for each element of a list.
+export function list<T>(f: Codec<T>): Codec<T[]> {
+	return (v: any[]) => v.map(f)
+}
+
+// dict returns a codec that calls f for each value of a dict.
+export function dict<T>(f: Codec<T>): Codec<Record<string, T>> {
+	return (v: Record<string, any>) => {
+		const res: Record<string, T> = {}
+		for (const k of Object.keys(v)) res[k] = f(v[k])
+		return res
+	}
+}
+
+// decodeTime parses a xelf time string. Dates without time are read in the local timezone.
+export function decodeTime(v: string): Date {
+	if (v.length === 10) v += 'T00:00'
+	const d = new Date(v)
+	if (isNaN(d.getTime())) throw new Error(`invalid time ${v}`)
+	return d
+}
+
+// encodeTime formats d as xelf time string in UTC.
+export function encodeTime(d: Date): string {
+	return d.toISOString()
+}
+
+// decodeSpan parses a xelf span string and returns the number of milliseconds.
+export function decodeSpan(v: string): number {
+	const m = /^(-)?(?:(?:(\d+):)?(\d+):)?(\d+)(?:\.(\d{0,9}))?$/.exec(v)
+	if (!m) throw new Error(`invalid span ${v}`)
+	let res = (parseInt(m[2] || '0') * 60 + parseInt(m[3] || '0')) * 60 + parseInt(m[4])
+	res *= 1000
+	if (m[5]) res += parseInt((m[5] + '000').slice(0, 3))
+	return m[1] ? -res : res
+}
+
+// encodeSpan formats the number of milliseconds n as xelf span string.
+export function encodeSpan(n: number): string {
+	const neg = n < 0
+	if (neg) n = -n
+	const ms = n % 1000
+	let s = Math.floor(n / 1000)
+	const h = Math.floor(s / 3600)
+	const m = Math.floor((s % 3600) / 60)
+	s %= 60
+	let res = `${h}:${pad(m, 2)}:${pad(s, 2)}`
+	if (ms) res += '.' + pad(ms, 3)
+	return neg ? '-' + res : res
+}
+
+function pad(n: number, w: number): string {
+	return String(n).padStart(w, '0')
+}
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lit"
+)
+
+// Failure is the cause of a failed assertion or expectation.
+type Failure struct {
+	Msg string
+	// Got is the evaluated value or nil.
+	Got lit.Val
+	// Want is the expected value or nil.
+	Want lit.Val
+	// Diff is the delta from got to want for mismatched values.
+	Diff lit.Delta
+}
+
+func (f *Failure) Error() string {
+	var b strings.Builder
+	b.WriteString(f.Msg)
+	if f.Want != nil {
+		fmt.Fprintf(&b, "\n\twant: %s", bfr.String(f.Want))
+	}
+	if f.Got != nil {
+		fmt.Fprintf(&b, "\n\tgot:  %s", bfr.String(f.Got))
+	}
+	if len(f.Diff) > 0 {
+		fmt.Fprintf(&b, "\n\tdiff: %s", f.Diff)
+	}
+	return b.String()
+}
+
+var (
+	Assert    = &assertSpec{impl("<form@assert cond:any msg?:str bool>")}
+	ExpectEq  = &expectEqSpec{impl("<form@expect_eq got:any want:any bool>")}
+	ExpectErr = &expectErrSpec{impl("<form@expect_err x:any code?:int bool>")}
+)
+
+type assertSpec struct{ exp.SpecBase }
+
+func (s *assertSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	v, err := p.Eval(c.Env, c.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !v.Zero() {
+		return lit.Bool(true), nil
+	}
+	msg := fmt.Sprintf("assert %s", c.Args[0])
+	if c.Args[1] != nil {
+		m, err := p.Eval(c.Env, c.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		msg = m.String()
+	}
+	return nil, ast.ErrFailed(c.Src, c.Sig.Ref, &Failure{Msg: msg, Got: v})
+}
+
+type expectEqSpec struct{ exp.SpecBase }
+
+func (s *expectEqSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	args, err := p.EvalArgs(c)
+	if err != nil {
+		return nil, err
+	}
+	got, want := args[0], args[1]
+	if lit.Equal(got, want) {
+		return lit.Bool(true), nil
+	}
+	f := &Failure{Msg: fmt.Sprintf("expect %s", c.Args[0]), Got: got, Want: want}
+	f.Diff, _ = lit.Diff(got, want)
+	return nil, ast.ErrFailed(c.Src, c.Sig.Ref, f)
+}
+
+type expectErrSpec struct{ exp.SpecBase }
+
+// Eval evaluates the first argument and succeeds only if it returns an error. The optional code
+// must match any error code in the error chain. Resolution errors are not caught.
+func (s *expectErrSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	var code int64
+	if c.Args[1] != nil {
+		v, err := p.Eval(c.Env, c.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		n, err := lit.ToInt(v)
+		if err != nil {
+			return nil, err
+		}
+		code = int64(n)
+	}
+	v, err := p.Eval(c.Env, c.Args[0])
+	if err == nil {
+		msg := fmt.Sprintf("expect error for %s", c.Args[0])
+		return nil, ast.ErrFailed(c.Src, c.Sig.Ref, &Failure{Msg: msg, Got: v})
+	}
+	if code > 0 && !ast.HasCode(err, uint(code)) {
+		msg := fmt.Sprintf("expect error E%d for %s got: %v", code, c.Args[0], err)
+		return nil, ast.ErrFailed(c.Src, c.Sig.Ref, &Failure{Msg: msg})
+	}
+	return lit.Bool(true), nil
+}
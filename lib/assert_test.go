@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/exp"
+)
+
+func TestAssertEval(t *testing.T) {
+	tests := []struct {
+		raw  string
+		code uint
+		diff string
+	}{
+		{`(assert true)`, 0, ""},
+		{`(assert (eq 1 1) 'one')`, 0, ""},
+		{`(assert (eq 1 2))`, 601, ""},
+		{`(assert false 'custom')`, 601, ""},
+		{`(expect_eq (add 1 2) 3)`, 0, ""},
+		{`(expect_eq {a:1 b:2} {a:1 b:3})`, 601, "{b:3}"},
+		{`(expect_eq [1 2] [1 3])`, 601, `{.1:3}`},
+		{`(expect_err (err))`, 0, ""},
+		{`(expect_err (err) 600)`, 0, ""},
+		{`(expect_err (err) 510)`, 601, ""},
+		{`(expect_err 1)`, 601, ""},
+	}
+	for _, test := range tests {
+		_, err := exp.NewProg(Test).RunStr(test.raw, nil)
+		if test.code == 0 {
+			if err != nil {
+				t.Errorf("eval %s failed: %v", test.raw, err)
+			}
+			continue
+		}
+		if !ast.HasCode(err, test.code) {
+			t.Errorf("eval %s want error E%d got %v", test.raw, test.code, err)
+			continue
+		}
+		var f *Failure
+		if !errors.As(err, &f) {
+			t.Errorf("eval %s want failure got %v", test.raw, err)
+			continue
+		}
+		if got := f.Diff.String(); test.diff != "" && got != test.diff {
+			t.Errorf("eval %s want diff %s got %s", test.raw, test.diff, got)
+		}
+	}
+}
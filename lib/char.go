@@ -13,6 +13,8 @@ var Cat = &catSpec{impl("<form@cat tupl str>")}
 
 type catSpec struct{ exp.SpecBase }
 
+func (s *catSpec) Pure() bool { return true }
+
 func (s *catSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	tupl := c.Args[0].(*exp.Tupl)
 	return cat(p, c.Env, nil, tupl.Els)
@@ -37,6 +39,8 @@ var Sep = &sepSpec{impl("<form@sep str tupl str>")}
 
 type sepSpec struct{ exp.SpecBase }
 
+func (s *sepSpec) Pure() bool { return true }
+
 func (s *sepSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -64,6 +68,8 @@ type rawSpec struct {
 	JSON bool
 }
 
+func (s *rawSpec) Pure() bool { return true }
+
 func (s *rawSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -28,6 +28,36 @@ func (s *ifSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	return lit.ZeroWrap(rt), nil
 }
 
+// Fold drops branches with literal false conditions and returns the first branch if its
+// condition is a literal true.
+func (s *ifSpec) Fold(p *exp.Prog, c *exp.Call) (exp.Exp, error) {
+	tupl := c.Args[0].(*exp.Tupl)
+	els := tupl.Els[:0]
+	for i := 0; i < len(tupl.Els); i += 2 {
+		cond, then := tupl.Els[i], tupl.Els[i+1]
+		if l, ok := cond.(*exp.Lit); ok {
+			if l.Val.Zero() {
+				continue
+			}
+			if len(els) == 0 {
+				return then, nil
+			}
+			// the following branches are unreachable
+			c.Args[1] = then
+			break
+		}
+		els = append(els, cond, then)
+	}
+	tupl.Els = els
+	if len(els) > 0 {
+		return c, nil
+	}
+	if c.Args[1] != nil {
+		return c.Args[1], nil
+	}
+	return exp.LitSrc(lit.ZeroWrap(exp.SigRes(c.Sig).Type), c.Src), nil
+}
+
 var Swt = &swtSpec{impl("<form@swt @1 <tupl case:@1 then:exp|@2> else:exp?|@2 @2>")}
 
 type swtSpec struct{ exp.SpecBase }
@@ -56,10 +86,34 @@ func (s *swtSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	return lit.ZeroWrap(rt), nil
 }
 
+// Fold returns the matching branch if the argument and all cases up to the match are literals.
+func (s *swtSpec) Fold(p *exp.Prog, c *exp.Call) (exp.Exp, error) {
+	arg, ok := c.Args[0].(*exp.Lit)
+	if !ok {
+		return c, nil
+	}
+	els := c.Args[1].(*exp.Tupl).Els
+	for i := 0; i < len(els); i += 2 {
+		cas, ok := els[i].(*exp.Lit)
+		if !ok {
+			return c, nil
+		}
+		if lit.Equal(arg.Val, cas.Val) {
+			return els[i+1], nil
+		}
+	}
+	if c.Args[2] != nil {
+		return c.Args[2], nil
+	}
+	return exp.LitSrc(lit.ZeroWrap(exp.SigRes(c.Sig).Type), c.Src), nil
+}
+
 var Df = &dfSpec{impl("<form@df tupl|@1 @1!>")}
 
 type dfSpec struct{ exp.SpecBase }
 
+func (s *dfSpec) Pure() bool { return true }
+
 func (s *dfSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	// cases
 	for _, cas := range c.Args[0].(*exp.Tupl).Els {
@@ -12,6 +12,8 @@ var Len = &lenSpec{impl("<form@len <alt? list keyr str raw> int>")}
 
 type lenSpec struct{ exp.SpecBase }
 
+func (s *lenSpec) Pure() bool { return true }
+
 func (s *lenSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"xelf.org/xelf/exp"
+)
+
+// Docs holds the documentation for all specs in Std and Test.
+var Docs = exp.Docs{
+	"or": doc("or returns whether any argument is not zero. Evaluation stops at the first true value.",
+		ex(`(or false 1)`, `true`), ex(`(or)`, `false`)),
+	"ok": doc("ok returns whether all arguments are not zero. It returns false without arguments.",
+		ex(`(ok true 1)`, `true`), ex(`(ok)`, `false`)),
+	"and": doc("and returns whether all arguments are not zero. It returns true without arguments.",
+		ex(`(and true 0)`, `false`), ex(`(and)`, `true`)),
+	"not": doc("not returns whether all arguments are zero. It returns true without arguments.",
+		ex(`(not false 0)`, `true`), ex(`(not true)`, `false`)),
+	"err": doc("err returns a user error mentioning the call with all its arguments.",
+		ex(`(or true (err 'unreachable'))`, `true`)),
+	"add": doc("add returns the sum of all arguments. The result type is that of the first argument.",
+		ex(`(add 1 2 3)`, `6`), ex(`(add (int 1) 2)`, `3`)),
+	"sub": doc("sub subtracts all following arguments from the first argument.",
+		ex(`(sub 3 2 1)`, `0`)),
+	"mul": doc("mul returns the product of all arguments.",
+		ex(`(mul 1 2 3)`, `6`)),
+	"div": doc("div divides the first argument by all following arguments.",
+		ex(`(div 5 2)`, `2.5`)),
+	"rem": doc("rem returns the integer remainder of the first divided by the second argument.",
+		ex(`(rem 5 3)`, `2`), ex(`(rem -5 3)`, `-2`)),
+	"abs": doc("abs returns the absolute value of a number.",
+		ex(`(abs -1.2)`, `1.2`)),
+	"neg": doc("neg returns the negated value of a number.",
+		ex(`(neg 1)`, `-1`)),
+	"min": doc("min returns the smallest of all arguments.",
+		ex(`(min 3 1 2)`, `1`)),
+	"max": doc("max returns the largest of all arguments.",
+		ex(`(max 1 3 2)`, `3`)),
+	"eq": doc("eq returns whether all arguments are equal to the first argument.",
+		ex(`(eq 2 2 2)`, `true`), ex(`(eq 2 2 1)`, `false`)),
+	"equal": doc("equal returns whether all arguments are equal to the first argument.",
+		ex(`(equal 'a' 'a')`, `true`)),
+	"ne": doc("ne returns whether all arguments are not equal to the first argument.",
+		ex(`(ne 1 2 3)`, `true`)),
+	"lt": doc("lt returns whether all arguments are in strictly ascending order.",
+		ex(`(lt 1 2 3)`, `true`), ex(`(lt 2 3 1)`, `false`)),
+	"le": doc("le returns whether all arguments are in ascending order.",
+		ex(`(le 1 1 2)`, `true`)),
+	"gt": doc("gt returns whether all arguments are in strictly descending order.",
+		ex(`(gt 3 2 1)`, `true`)),
+	"ge": doc("ge returns whether all arguments are in descending order.",
+		ex(`(ge 2 2 1)`, `true`)),
+	"in": doc("in returns whether the first argument is an element of any of the following lists.",
+		ex(`(in 2 [1 2])`, `true`), ex(`(in 3 [1 2] [4])`, `false`)),
+	"ni": doc("ni returns whether the first argument is not an element of the following lists.",
+		ex(`(ni 3 [1 2])`, `true`)),
+	"if": params(doc("if evaluates the then expression of the first condition that is not zero, or the "+
+		"else expression. It returns the zero value if no expression was evaluated.",
+		ex(`(if false 1 2)`, `2`), ex(`(if 0 'zero' 1 'one')`, `'one'`), ex(`(if false 1)`, `0`)),
+		"cond", "a condition evaluated in order", "then", "the expression for a true condition",
+		"else", "the expression if no condition is true"),
+	"swt": params(doc("swt evaluates the then expression of the first case equal to the first argument, "+
+		"or the else expression. It returns the zero value if no expression was evaluated.",
+		ex(`(swt 2 1 'one' 2 'two')`, `'two'`), ex(`(swt 0 1 'one' 'else')`, `'else'`)),
+		"case", "a value compared to the first argument", "then", "the expression for a matching case",
+		"else", "the expression if no case matches"),
+	"df": doc("df returns the first argument that is not zero or the zero value.",
+		ex(`(df 0 1 2)`, `1`), ex(`(df null 'none')`, `'none'`)),
+	"cat": doc("cat returns the concatenation of all arguments formatted as plain strings.",
+		ex(`(cat 'Hallo' 'Welt' '!')`, `'HalloWelt!'`)),
+	"sep": doc("sep returns all but the first argument formatted as plain strings separated by the first.",
+		ex(`(sep ' ' 'Hallo' 'Welt')`, `'Hallo Welt'`)),
+	"xelf": doc("xelf returns the argument in xelf format as raw bytes.",
+		ex(`(xelf 'Hallo')`, `'\'Hallo\''`)),
+	"json": doc("json returns the argument in json format as raw bytes.",
+		ex(`(json 'Hallo')`, `'"Hallo"'`)),
+	"make": doc("make returns a new value of a type that is initialized with the optional arguments.",
+		ex(`(make int)`, `0`), ex(`(make list|int + 1 2)`, `[1 2]`), ex(`(make dict a:1 b:2)`, `{a:1 b:2}`)),
+	"sel": doc("sel selects a path from the value of a symbol. The path can be a symbol or key and "+
+		"index arguments.",
+		ex(`(with {a:1 b:2} (sel .$ 'a'))`, `1`), ex(`(with [1 2] (sel .$ -1))`, `2`)),
+	"len": doc("len returns the length of a string, raw bytes, a list or a keyr.",
+		ex(`(len 'test')`, `4`), ex(`(len [1 2 3])`, `3`)),
+	"do": doc("do evaluates all arguments in order and returns the last result.",
+		ex(`(do 1 2)`, `2`)),
+	"call": doc("call calls a spec value with the remaining arguments.",
+		ex(`(call add 1 2 3)`, `6`)),
+	"with": params(doc("with evaluates the last expression in a new scope. The scope uses the first argument "+
+		"as dot value or defines the tagged arguments as local names.",
+		ex(`(with 1 (add 2 .))`, `3`), ex(`(with a:1 b:(add a 1) (add a b))`, `3`)),
+		"dot", "the dot value of the new scope", "lets", "tagged values defined in the new scope"),
+	"mut": doc("mut returns the first argument modified by the tagged deltas or appended values.",
+		ex(`(mut {a:1} b:2)`, `{a:1 b:2}`), ex(`(mut [3]+ 2 1)`, `[3 2 1]`)),
+	"fn": doc("fn returns a new function spec for the last expression. Parameters can be declared as "+
+		"tagged types or are inferred from the use of underscore and .1 style symbols.",
+		ex(`((fn (add _ 1)) 2)`, `3`), ex(`((fn a:int b:int (sub .a .b)) 1 2)`, `-1`)),
+	"fold": doc("fold accumulates the result of calling a function with the previous result and each "+
+		"element of a list from first to last.",
+		ex(`(fold [1 2 3] '' (fn (cat _ .1)))`, `'123'`)),
+	"foldr": doc("foldr accumulates the result of calling a function with the previous result and each "+
+		"element of a list from last to first.",
+		ex(`(foldr [1 2 3] '' (fn (cat _ .1)))`, `'321'`)),
+	"range": params(doc("range returns a list of n integers starting at zero or the results of calling the "+
+		"optional function with each of them.",
+		ex(`(range 3)`, `[0 1 2]`), ex(`(range 3 (fn (add _ 1)))`, `[1 2 3]`)),
+		"n", "the number of elements", "f", "an optional function called with each index"),
+	"assert": doc("assert returns true if the condition is not zero and fails with the optional "+
+		"message otherwise.",
+		ex(`(assert (eq 1 1) 'one is one')`, `true`)),
+	"expect_eq": doc("expect_eq returns true if both arguments are equal and otherwise fails with the "+
+		"delta from the first to the second value.",
+		ex(`(expect_eq (add 1 2) 3)`, `true`)),
+	"expect_err": doc("expect_err returns true if evaluating the first argument fails with an error "+
+		"that has the optional error code.",
+		ex(`(expect_err (err) 600)`, `true`)),
+}
+
+func doc(summary string, exs ...exp.Example) *exp.Doc {
+	return &exp.Doc{Summary: summary, Examples: exs}
+}
+
+func params(d *exp.Doc, kvs ...string) *exp.Doc {
+	for i := 0; i+1 < len(kvs); i += 2 {
+		d.Params = append(d.Params, exp.ParamDoc{Name: kvs[i], Doc: kvs[i+1]})
+	}
+	return d
+}
+
+func ex(code, want string) exp.Example { return exp.Example{Code: code, Want: want} }
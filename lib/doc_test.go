@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"testing"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/exp"
+)
+
+func TestDocs(t *testing.T) {
+	for name, s := range Test {
+		d := Docs.Spec(s)
+		if d == nil || d.Summary == "" {
+			t.Errorf("missing doc for %s", name)
+			continue
+		}
+		for _, e := range d.Examples {
+			got, err := exp.NewProg(Test).RunStr(e.Code, nil)
+			if err != nil {
+				t.Errorf("%s example %s: %v", name, e.Code, err)
+				continue
+			}
+			if str := bfr.String(got); str != e.Want {
+				t.Errorf("%s example %s want %s got %s", name, e.Code, e.Want, str)
+			}
+		}
+	}
+	for name := range Docs {
+		if Test[name] == nil {
+			t.Errorf("doc for unknown spec %s", name)
+		}
+	}
+}
+
+func TestDocLookup(t *testing.T) {
+	p := exp.NewProg(Std)
+	typ, d, err := Docs.Lookup(p, "range")
+	if err != nil {
+		t.Fatalf("lookup range: %v", err)
+	}
+	want := "### range\n\n" +
+		"\t<form@range n:int f?:<func int @1> list|@1>\n\n" +
+		"range returns a list of n integers starting at zero or the results of calling the optional " +
+		"function with each of them.\n\n" +
+		"Parameters:\n\n" +
+		" * `n` the number of elements\n" +
+		" * `f` an optional function called with each index\n\n" +
+		"Examples:\n\n" +
+		"\t(range 3)\n\t# [0 1 2]\n" +
+		"\t(range 3 (fn (add _ 1)))\n\t# [1 2 3]\n\n"
+	if got := d.Markdown("range", typ); got != want {
+		t.Errorf("range markdown want:\n%s\ngot:\n%s", want, got)
+	}
+	if _, _, err := Docs.Lookup(p, "unknown"); err == nil {
+		t.Errorf("want error for unknown spec")
+	}
+}
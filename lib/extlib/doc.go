@@ -0,0 +1,63 @@
+package extlib
+
+import (
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+)
+
+// Docs holds the documentation for all specs in Std.
+var Docs = make(exp.Docs).AddMap(lib.Docs).AddMap(exp.Docs{
+	"index": doc("index returns the index of the first substring in a string or -1.",
+		ex(`(index 'chicken' 'ken')`, `4`)),
+	"prefix": doc("prefix returns whether a string starts with a prefix.",
+		ex(`(prefix 'xelf' 'xe')`, `true`)),
+	"suffix": doc("suffix returns whether a string ends with a suffix.",
+		ex(`(suffix 'xelf' 'lf')`, `true`)),
+	"contains": doc("contains returns whether a string contains a substring.",
+		ex(`(contains 'xelf' 'el')`, `true`)),
+	"upper": doc("upper returns a string with all letters mapped to upper case.",
+		ex(`(upper 'xelf')`, `'XELF'`)),
+	"lower": doc("lower returns a string with all letters mapped to lower case.",
+		ex(`(lower 'XELF')`, `'xelf'`)),
+	"trim": doc("trim returns a string without leading and trailing white space.",
+		ex(`(trim ' xelf ')`, `'xelf'`)),
+	"like": doc("like returns whether a string matches a sql like pattern with % and _ wildcards.",
+		ex(`(like 'xelf' 'x%')`, `true`)),
+	"ilike": doc("ilike returns whether a string matches a case insensitive sql like pattern.",
+		ex(`(ilike 'XELF' 'x%')`, `true`)),
+	"add_span": doc("add_span returns a time with a span added.",
+		ex(`(add_span (time '2020-01-01T12:00:00Z') (span '1:00:00'))`, `'2020-01-01T13:00:00Z'`)),
+	"add_days": doc("add_days returns a time with a number of days added.",
+		ex(`(add_days (time '2020-01-31T00:00:00Z') 1)`, `'2020-02-01T00:00:00Z'`)),
+	"add_date": doc("add_date returns a time with a number of years, months and days added.",
+		ex(`(add_date (time '2020-01-31T00:00:00Z') 1 0 1)`, `'2021-02-01T00:00:00Z'`)),
+	"sub_time": doc("sub_time returns the span between the first and the second time.",
+		ex(`(sub_time (time '2020-01-02T00:00:00Z') (time '2020-01-01T00:00:00Z'))`, `'24:00:00'`)),
+	"year": doc("year returns the year of a time.",
+		ex(`(year (time '2020-01-02T00:00:00Z'))`, `2020`)),
+	"month": doc("month returns the month of a time from 1 to 12.",
+		ex(`(month (time '2020-03-02T00:00:00Z'))`, `3`)),
+	"weekday": doc("weekday returns the day of the week of a time starting with sunday as 0.",
+		ex(`(weekday (time '2020-01-05T00:00:00Z'))`, `0`)),
+	"yearday": doc("yearday returns the day of the year of a time starting at 1.",
+		ex(`(yearday (time '2020-02-01T00:00:00Z'))`, `32`)),
+	"day_start": doc("day_start returns the start of the day of a time.",
+		ex(`(day_start (time '2020-01-02T12:30:00Z'))`, `'2020-01-02T00:00:00Z'`)),
+	"day_end": doc("day_end returns the last second of the day of a time.",
+		ex(`(day_end (time '2020-01-02T12:30:00Z'))`, `'2020-01-02T23:59:59Z'`)),
+	"time_format": doc("time_format returns a time formatted with a go time layout.",
+		ex(`(time_format (time '2020-01-02T00:00:00Z') '02.01.2006')`, `'02.01.2020'`)),
+	"fmt_date": doc("fmt_date returns the date of a time formatted as 2006-01-02.",
+		ex(`(fmt_date (time '2020-01-02T12:30:00Z'))`, `'2020-01-02'`)),
+	"fmt_time": doc("fmt_time returns the time of day formatted as 15:04:05.",
+		ex(`(fmt_time (time '2020-01-02T12:30:00Z'))`, `'12:30:00'`)),
+	"fmt_human": doc("fmt_human returns the time of day formatted as 15:04:05.",
+		ex(`(fmt_human (time '2020-01-02T12:30:00Z'))`, `'12:30:00'`)),
+	"new_uuid": doc("new_uuid returns a new random uuid."),
+})
+
+func doc(summary string, exs ...exp.Example) *exp.Doc {
+	return &exp.Doc{Summary: summary, Examples: exs}
+}
+
+func ex(code, want string) exp.Example { return exp.Example{Code: code, Want: want} }
@@ -0,0 +1,28 @@
+package extlib
+
+import (
+	"testing"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/exp"
+)
+
+func TestDocs(t *testing.T) {
+	for name, s := range Std {
+		d := Docs.Spec(s)
+		if d == nil || d.Summary == "" {
+			t.Errorf("missing doc for %s", name)
+			continue
+		}
+		for _, e := range d.Examples {
+			got, err := exp.NewProg(Std).RunStr(e.Code, nil)
+			if err != nil {
+				t.Errorf("%s example %s: %v", name, e.Code, err)
+				continue
+			}
+			if str := bfr.String(got); str != e.Want {
+				t.Errorf("%s example %s want %s got %s", name, e.Code, e.Want, str)
+			}
+		}
+	}
+}
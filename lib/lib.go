@@ -23,6 +23,12 @@ var Std = exp.Builtins(make(Specs).AddMap(Core).Add(
 	Fold, Foldr, Range,
 ))
 
+// Asserts holds the assert and expect specs used by test cases.
+var Asserts = exp.Builtins(make(Specs).Add(Assert, ExpectEq, ExpectErr))
+
+// Test extends the standard environment with the assert and expect specs.
+var Test = exp.Builtins(make(Specs).AddMap(Std).AddMap(Asserts))
+
 // Specs is spec map helper that can be converted to a builtin environment.
 type Specs map[string]exp.Spec
 
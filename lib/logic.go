@@ -18,6 +18,8 @@ type logicSpec struct {
 	zero, init, neg bool
 }
 
+func (s *logicSpec) Pure() bool { return true }
+
 func (s *logicSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	r := s.zero
 	args := c.Args[0].(*exp.Tupl).Els
@@ -11,6 +11,8 @@ var Add = &addSpec{impl("<form@add num@ tupl?|num _>")}
 
 type addSpec struct{ exp.SpecBase }
 
+func (s *addSpec) Pure() bool { return true }
+
 func (s *addSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	val, err := p.Eval(c.Env, c.Args[0])
 	if err != nil {
@@ -47,6 +49,8 @@ var Mul = &mulSpec{impl("<form@mul num@ tupl?|num _>")}
 
 type mulSpec struct{ exp.SpecBase }
 
+func (s *mulSpec) Pure() bool { return true }
+
 func (s *mulSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -70,6 +74,8 @@ var Sub = &subSpec{impl("<form@sub num@ tupl|num _>")}
 
 type subSpec struct{ exp.SpecBase }
 
+func (s *subSpec) Pure() bool { return true }
+
 func (s *subSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -94,6 +100,8 @@ var Div = &divSpec{impl("<form@div num@ tupl|num _>")}
 
 type divSpec struct{ exp.SpecBase }
 
+func (s *divSpec) Pure() bool { return true }
+
 func (s *divSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -118,6 +126,8 @@ var Rem = &remSpec{impl("<form@rem int int int>")}
 
 type remSpec struct{ exp.SpecBase }
 
+func (s *remSpec) Pure() bool { return true }
+
 func (s *remSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -138,6 +148,8 @@ var Abs = &absSpec{impl("<form@abs num@ _>")}
 
 type absSpec struct{ exp.SpecBase }
 
+func (s *absSpec) Pure() bool { return true }
+
 func (s *absSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -157,6 +169,8 @@ var Neg = &negSpec{impl("<form@neg num@ _>")}
 
 type negSpec struct{ exp.SpecBase }
 
+func (s *negSpec) Pure() bool { return true }
+
 func (s *negSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -173,6 +187,8 @@ var Min = &minSpec{impl("<form@min num@ tupl?|num _>")}
 
 type minSpec struct{ exp.SpecBase }
 
+func (s *minSpec) Pure() bool { return true }
+
 func (s *minSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -198,6 +214,8 @@ var Max = &maxSpec{impl("<form@max num@ tupl?|num _>")}
 
 type maxSpec struct{ exp.SpecBase }
 
+func (s *maxSpec) Pure() bool { return true }
+
 func (s *maxSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -13,6 +13,8 @@ type compSpec struct {
 	neg  bool
 }
 
+func (s *compSpec) Pure() bool { return true }
+
 var (
 	Eq    = &compSpec{impl("<form@eq any tupl bool>"), 0, false}
 	Equal = &compSpec{impl("<form@equal any tupl bool>"), 0, false}
@@ -64,6 +66,8 @@ type inSpec struct {
 	neg bool
 }
 
+func (s *inSpec) Pure() bool { return true }
+
 func (s *inSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	args, err := p.EvalArgs(c)
 	if err != nil {
@@ -0,0 +1,152 @@
+// Package lint provides static checks for xelf programs with pluggable rules.
+//
+// Programs are resolved but never evaluated. The linter collects all calls and symbols of the
+// input before resolution. Resolution updates them in place, so rules can inspect the resolved
+// spec and environment of every call and symbol, even those inside of function bodies or those
+// that were replaced by a literal in the resolved program.
+package lint
+
+import (
+	"io"
+	"sort"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Rule checks a resolved program and reports problems as errors with lint codes.
+type Rule interface {
+	// Name returns a short name to identify the rule.
+	Name() string
+	// Check returns all problems the rule finds in the program.
+	Check(c *Ctx) []*ast.Error
+}
+
+// NewRule returns a rule with the given name that uses the check function.
+func NewRule(name string, check func(*Ctx) []*ast.Error) Rule {
+	return &rule{name, check}
+}
+
+type rule struct {
+	name  string
+	check func(*Ctx) []*ast.Error
+}
+
+func (r *rule) Name() string              { return r.name }
+func (r *rule) Check(c *Ctx) []*ast.Error { return r.check(c) }
+
+// Ctx is a resolved program with all calls and symbols of its input.
+type Ctx struct {
+	Prog *exp.Prog
+	// Exp is the resolved program expression.
+	Exp exp.Exp
+	// Calls holds all calls of the input in source order. Calls that were not resolved have no spec.
+	Calls []*exp.Call
+	// Syms holds all symbols of the input in source order. Their env is the resolving environment.
+	Syms []*exp.Sym
+	// Refs holds the type reference names of all type literals of the input.
+	Refs []string
+
+	parent map[*exp.Call]*exp.Call
+}
+
+// Parent returns the call that encloses c in the input or nil.
+func (c *Ctx) Parent(call *exp.Call) *exp.Call { return c.parent[call] }
+
+// Linter resolves programs in an environment and checks them with a list of rules.
+type Linter struct {
+	Env   exp.Env
+	Rules []Rule
+}
+
+// New returns a linter for env with the given rules or the default rules.
+func New(env exp.Env, rules ...Rule) *Linter {
+	if len(rules) == 0 {
+		rules = Default
+	}
+	return &Linter{Env: env, Rules: rules}
+}
+
+// Read reads, resolves and checks the named program from r. It returns the problems sorted by
+// source position or an error if the program could not be parsed or resolved.
+func (l *Linter) Read(r io.Reader, name string) ([]*ast.Error, error) {
+	x, err := exp.Read(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return l.Lint(x, name)
+}
+
+// Lint resolves and checks the program expression x with file url. It returns the problems
+// sorted by source position or an error if the program could not be resolved.
+func (l *Linter) Lint(x exp.Exp, url string) ([]*ast.Error, error) {
+	c := &Ctx{parent: make(map[*exp.Call]*exp.Call)}
+	c.collect(x, nil)
+	c.Prog = exp.NewProg(l.Env)
+	c.Prog.File.URL = url
+	x, err := c.Prog.Resl(c.Prog, x, typ.Void)
+	if err != nil {
+		return nil, err
+	}
+	c.Exp = x
+	var res []*ast.Error
+	for _, r := range l.Rules {
+		res = append(res, r.Check(c)...)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i].Src.Pos, res[j].Src.Pos
+		return a.Line < b.Line || a.Line == b.Line && a.Byte < b.Byte
+	})
+	return res, nil
+}
+
+func (c *Ctx) collect(x exp.Exp, par *exp.Call) {
+	switch a := x.(type) {
+	case *exp.Sym:
+		c.Syms = append(c.Syms, a)
+	case *exp.Tag:
+		if a.Exp != nil {
+			c.collect(a.Exp, par)
+		}
+	case *exp.Tupl:
+		for _, el := range a.Els {
+			c.collect(el, par)
+		}
+	case *exp.Call:
+		c.Calls = append(c.Calls, a)
+		if par != nil {
+			c.parent[a] = par
+		}
+		for _, arg := range a.Args {
+			if arg != nil {
+				c.collect(arg, a)
+			}
+		}
+	case *exp.Lit:
+		var t typ.Type
+		switch v := lit.Unwrap(a.Val).(type) {
+		case typ.Type:
+			t = v
+		case *typ.Type:
+			t = *v
+		default:
+			return
+		}
+		typ.Edit(t, func(e *typ.Editor) (typ.Type, error) {
+			if e.Ref != "" {
+				c.Refs = append(c.Refs, e.Ref)
+			}
+			return e.Type, nil
+		})
+	}
+}
+
+// SpecOf returns the resolved spec of call c with spec references unwrapped or nil.
+func SpecOf(c *exp.Call) exp.Spec {
+	if r := exp.UnwrapSpec(c.Spec); r != nil && r.Spec != nil {
+		return r.Spec
+	}
+	return c.Spec
+}
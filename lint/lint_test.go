@@ -0,0 +1,110 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/mod"
+)
+
+func testEnv() exp.Env {
+	setup := func(prog *exp.Prog, s *mod.Src) (*mod.File, error) {
+		f := &exp.File{URL: s.URL}
+		m := &exp.Mod{File: f, Name: "foo", Decl: lit.MakeObj(lit.Keyed{
+			{Key: "b", Val: new(lit.IntMut)},
+		})}
+		return f, f.AddRefs(exp.ModRef{Pub: true, Mod: m})
+	}
+	mods := new(mod.SysMods)
+	mods.Register(&mod.Src{Rel: "test/foo", Loc: mod.Loc{URL: "xelf:test/foo"}, Setup: setup})
+	return mod.NewLoaderEnv(exp.Builtins(lib.Std), mods)
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{`(with a:1 b:2 (add a 1))`, []string{"1:10 E701 with binding b is never used"}},
+		{`(with a:1 b:(add a 1) b)`, nil},
+		{`(with a:1 (with a:2 a))`, []string{
+			"1:6 E701 with binding a is never used",
+			"1:16 E702 a shadows the enclosing with binding",
+		}},
+		{`(fn n:int (fn n:int m:int (add .n .m)))`, []string{
+			"1:14 E702 n shadows the enclosing func parameter",
+		}},
+		{`(fn n:int (with n:1 (add n .n)))`, nil},
+		{`(swt 1 1 'a' 2 'b' 1 'c')`, []string{"1:19 E703 case 1 is unreachable"}},
+		{`(if true 1 2)`, []string{"1:4 E704 condition true is always true"}},
+		{`(if (eq 1 2) 1 0 2 3)`, []string{"1:15 E704 condition 0 is always false"}},
+		{`(fn n:int (if false .n))`, []string{"1:14 E704 condition false is always false"}},
+		{`(import 'test/foo') foo.b`, nil},
+		{`(import f:'test/foo') f.b`, nil},
+		{`(import 'test/foo' use:['b']) b`, nil},
+		{`(import 'test/foo') 1`, []string{"1:8 E706 import test/foo is never used"}},
+		{`(import f:'test/foo') 1`, []string{"1:10 E706 import test/foo is never used"}},
+	}
+	l := New(testEnv())
+	for _, test := range tests {
+		res, err := l.Read(strings.NewReader(test.raw), "")
+		if err != nil {
+			t.Errorf("lint %s failed: %v", test.raw, err)
+			continue
+		}
+		got := format(res)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("lint %s\nwant %q\n got %q", test.raw, test.want, got)
+		}
+	}
+}
+
+func TestLintDeprecated(t *testing.T) {
+	docs := exp.Docs{"sep": &exp.Doc{Deprecated: "use cat with a sep tag instead"}}
+	l := New(lib.Std, Deprecated(docs))
+	res, err := l.Read(strings.NewReader(`(sep ',' 'a' 'b')`), "")
+	if err != nil {
+		t.Fatalf("lint failed: %v", err)
+	}
+	if len(res) != 1 || res[0].Code != 705 || res[0].Help != docs["sep"].Deprecated {
+		t.Errorf("want deprecated error got %v", res)
+	}
+}
+
+func TestLintRule(t *testing.T) {
+	calls := NewRule("calls", func(c *Ctx) (res []*ast.Error) {
+		for _, call := range c.Calls {
+			if c.Parent(call) == nil {
+				continue
+			}
+			res = append(res, &ast.Error{Src: call.Src, Code: 700, Name: call.Sig.Ref})
+		}
+		return res
+	})
+	l := New(lib.Std, calls)
+	res, err := l.Read(strings.NewReader(`(add (sub 3 2) (mul 1 2))`), "")
+	if err != nil {
+		t.Fatalf("lint failed: %v", err)
+	}
+	want := []string{"1:5 E700 sub", "1:15 E700 mul"}
+	if got := format(res); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %q got %q", want, got)
+	}
+	_, err = l.Read(strings.NewReader(`(add 1 unknown)`), "")
+	if err == nil {
+		t.Errorf("want resolution error")
+	}
+}
+
+func format(errs []*ast.Error) (res []string) {
+	for _, e := range errs {
+		res = append(res, fmt.Sprintf("%d:%d E%d %s", e.Src.Line, e.Src.Byte, e.Code, e.Name))
+	}
+	return res
+}
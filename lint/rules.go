@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/mod"
+)
+
+// Default is the list of rules used by linters without explicit rules.
+var Default = []Rule{
+	UnusedLet, Shadow, UnreachableCase, ConstCond, Deprecated(lib.Docs), UnusedImport,
+}
+
+// UnusedLet reports with bindings that are never referenced.
+var UnusedLet = NewRule("unused-let", func(c *Ctx) (res []*ast.Error) {
+	type key struct {
+		env *lib.DotEnv
+		key string
+	}
+	used := make(map[key]bool)
+	for _, s := range c.Syms {
+		if de, ok := s.Env.(*lib.DotEnv); ok && len(s.Path) > 0 && s.Path[0].Sep() == 0 {
+			used[key{de, s.Path[0].Key}] = true
+		}
+	}
+	for _, call := range c.Calls {
+		de, ok := call.Env.(*lib.DotEnv)
+		if !ok || SpecOf(call) != lib.With {
+			continue
+		}
+		for _, tag := range tags(call.Args[1]) {
+			if !used[key{de, cor.Keyed(tag.Tag)}] {
+				res = append(res, &ast.Error{Src: tag.Src, Code: 701,
+					Name: fmt.Sprintf("with binding %s is never used", tag.Tag)})
+			}
+		}
+	}
+	return res
+})
+
+// Shadow reports with bindings that shadow bindings of an enclosing with expression and func
+// parameters that shadow parameters of an enclosing func.
+var Shadow = NewRule("shadow", func(c *Ctx) (res []*ast.Error) {
+	for _, call := range c.Calls {
+		sp, names := scope(call)
+		for _, tag := range names {
+			key := cor.Keyed(tag.Tag)
+			for par := c.Parent(call); par != nil; par = c.Parent(par) {
+				ps, pnames := scope(par)
+				if ps != sp || findTag(pnames, key) == nil {
+					continue
+				}
+				res = append(res, &ast.Error{Src: tag.Src, Code: 702,
+					Name: fmt.Sprintf("%s shadows the enclosing %s", tag.Tag, kindName(sp))})
+				break
+			}
+		}
+	}
+	return res
+})
+
+// scope returns the with or fn spec of call c and its bindings or parameters.
+func scope(c *exp.Call) (exp.Spec, []*exp.Tag) {
+	switch sp := SpecOf(c); sp {
+	case lib.With:
+		return sp, tags(c.Args[1])
+	case lib.Fn:
+		return sp, tags(c.Args[0])
+	}
+	return nil, nil
+}
+
+func kindName(sp exp.Spec) string {
+	if sp == lib.Fn {
+		return "func parameter"
+	}
+	return "with binding"
+}
+
+// UnreachableCase reports swt cases that are equal to a preceding case.
+var UnreachableCase = NewRule("unreachable-case", func(c *Ctx) (res []*ast.Error) {
+	for _, call := range c.Calls {
+		if SpecOf(call) != lib.Swt {
+			continue
+		}
+		els := elems(call.Args[1])
+		var seen []lit.Val
+	Cases:
+		for i := 0; i < len(els); i += 2 {
+			l, ok := els[i].(*exp.Lit)
+			if !ok {
+				continue
+			}
+			for _, v := range seen {
+				if lit.Equal(v, l.Val) {
+					res = append(res, &ast.Error{Src: l.Src, Code: 703,
+						Name: fmt.Sprintf("case %s is unreachable", l)})
+					continue Cases
+				}
+			}
+			seen = append(seen, l.Val)
+		}
+	}
+	return res
+})
+
+// ConstCond reports if conditions that are literals.
+var ConstCond = NewRule("const-cond", func(c *Ctx) (res []*ast.Error) {
+	for _, call := range c.Calls {
+		if SpecOf(call) != lib.If {
+			continue
+		}
+		els := elems(call.Args[0])
+		for i := 0; i < len(els); i += 2 {
+			if l, ok := els[i].(*exp.Lit); ok {
+				res = append(res, &ast.Error{Src: l.Src, Code: 704,
+					Name: fmt.Sprintf("condition %s is always %t", l, !l.Val.Zero())})
+			}
+		}
+	}
+	return res
+})
+
+// Deprecated returns a rule that reports calls to specs with a deprecation notice in docs.
+func Deprecated(docs exp.Docs) Rule {
+	return NewRule("deprecated", func(c *Ctx) (res []*ast.Error) {
+		for _, call := range c.Calls {
+			if call.Spec == nil {
+				continue
+			}
+			if d := docs.Spec(call.Spec); d != nil && d.Deprecated != "" {
+				res = append(res, &ast.Error{Src: call.Src, Code: 705,
+					Name: fmt.Sprintf("spec %s is deprecated", call.Sig.Ref),
+					Help: d.Deprecated})
+			}
+		}
+		return res
+	})
+}
+
+// UnusedImport reports imports of modules that are never referenced by a symbol or type.
+// Re-exported modules are always considered used.
+var UnusedImport = NewRule("unused-import", func(c *Ctx) (res []*ast.Error) {
+	for _, call := range c.Calls {
+		if SpecOf(call) != mod.Import {
+			continue
+		}
+		for _, el := range elems(call.Args[0]) {
+			var alias string
+			if t, ok := el.(*exp.Tag); ok {
+				if _, ok, _ := mod.UseTag(t); ok {
+					continue
+				}
+				alias, el = t.Tag, t.Exp
+			}
+			l, ok := el.(*exp.Lit)
+			if !ok {
+				continue
+			}
+			path := l.Value().String()
+			var found, used bool
+			for _, ref := range c.Prog.File.Refs {
+				if ref.Pub || ref.Path != path || alias != "" && ref.Alias != alias {
+					continue
+				}
+				found = true
+				if c.usesRef(ref) {
+					used = true
+					break
+				}
+			}
+			if found && !used {
+				res = append(res, &ast.Error{Src: el.Source(), Code: 706,
+					Name: fmt.Sprintf("import %s is never used", path)})
+			}
+		}
+	}
+	return res
+})
+
+// usesRef returns whether a program symbol or type literal references module ref.
+func (c *Ctx) usesRef(ref exp.ModRef) bool {
+	uses := func(name string) bool {
+		if q, _ := exp.SplitQualifier(name); q != "" {
+			return q == ref.Key()
+		}
+		return ref.Uses(name)
+	}
+	for _, s := range c.Syms {
+		// type symbols are resolved without updating the symbol env
+		if name := strings.TrimPrefix(s.Sym, "@"); name != s.Sym || s.Env == c.Prog {
+			if uses(name) {
+				return true
+			}
+		}
+	}
+	for _, name := range c.Refs {
+		if uses(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func elems(x exp.Exp) []exp.Exp {
+	if t, ok := x.(*exp.Tupl); ok {
+		return t.Els
+	}
+	return nil
+}
+
+func tags(x exp.Exp) (res []*exp.Tag) {
+	for _, el := range elems(x) {
+		if tag, ok := el.(*exp.Tag); ok {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+func findTag(ts []*exp.Tag, key string) *exp.Tag {
+	for _, t := range ts {
+		if cor.Keyed(t.Tag) == key {
+			return t
+		}
+	}
+	return nil
+}
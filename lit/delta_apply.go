@@ -50,11 +50,17 @@ func Apply(mut Mut, d Delta) (Mut, error) {
 		}
 		switch suf {
 		case '-':
-			return mut, applyDelete(mut, p)
+			err = applyDelete(mut, p)
 		case '*':
-			return mut, applyOps(mut, p, kv.Val, false)
+			err = applyOps(mut, p, kv.Val, false)
 		case '+':
-			return mut, applyOps(mut, p, kv.Val, true)
+			err = applyOps(mut, p, kv.Val, true)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if suf == '-' || suf == '*' || suf == '+' {
+			continue
 		}
 		if (len(p) == 0 || len(p) == 1 && p.Fst().Empty()) && kv.Nil() {
 			mut = AnyWrap(mut.Type())
@@ -8,6 +8,7 @@ import (
 
 	"xelf.org/xelf/ast"
 	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/cor"
 	"xelf.org/xelf/knd"
 	"xelf.org/xelf/typ"
 )
@@ -103,8 +104,7 @@ func (x *IntPrx) Parse(a ast.Ast) error {
 	if err != nil {
 		return err
 	}
-	x.elem().SetInt(n)
-	return nil
+	return x.Assign(Int(n))
 }
 func (x *IntPrx) Assign(v Val) error {
 	if v == nil || v.Nil() {
@@ -115,9 +115,9 @@ func (x *IntPrx) Assign(v Val) error {
 		return err
 	}
 	switch e := x.elem(); e.Kind() {
-	case reflect.Int64, reflect.Int, reflect.Int32, reflect.Int16:
+	case reflect.Int64, reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8:
 		e.SetInt(int64(n))
-	case reflect.Uint64, reflect.Uint, reflect.Uint32, reflect.Uint16:
+	case reflect.Uint64, reflect.Uint, reflect.Uint32, reflect.Uint16, reflect.Uint8:
 		e.SetUint(uint64(n))
 	default:
 		return fmt.Errorf("unexpected int proxy target %s", e.Type())
@@ -126,9 +126,9 @@ func (x *IntPrx) Assign(v Val) error {
 }
 func (x *IntPrx) value() int64 {
 	switch e := x.elem(); e.Kind() {
-	case reflect.Int64, reflect.Int, reflect.Int32:
+	case reflect.Int64, reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8:
 		return e.Int()
-	case reflect.Uint64, reflect.Uint, reflect.Uint32:
+	case reflect.Uint64, reflect.Uint, reflect.Uint32, reflect.Uint16, reflect.Uint8:
 		return int64(e.Uint())
 	default:
 		panic(fmt.Errorf("unexpected int proxy target %s", e.Type()))
@@ -210,3 +210,68 @@ func (x *RealPrx) String() string {
 func (x *RealPrx) MarshalJSON() ([]byte, error) { return []byte(x.String()), nil }
 func (x *RealPrx) UnmarshalJSON(b []byte) error { return x.unmarshal(b, x) }
 func (x *RealPrx) Print(p *bfr.P) error         { return p.Fmt(x.String()) }
+
+// StrPrx proxies named go string types, that are reflected as enum types.
+type StrPrx struct{ proxy }
+
+func (x *StrPrx) NewWith(v reflect.Value) Mut { return &StrPrx{x.with(v)} }
+
+func (x *StrPrx) New() Mut   { return x.NewWith(x.new()) }
+func (x *StrPrx) Zero() bool { return x.Nil() || x.value() == "" }
+func (x *StrPrx) Mut() Mut   { return x }
+func (x *StrPrx) Value() Val {
+	if x.Nil() {
+		return Null{}
+	}
+	return Str(x.value())
+}
+func (x *StrPrx) As(t typ.Type) (Val, error) {
+	if x.typ == t {
+		return x, nil
+	}
+	return &StrPrx{x.typed(t)}, nil
+}
+func (x *StrPrx) Parse(a ast.Ast) error {
+	if isNull(a) {
+		return x.setNull()
+	}
+	if a.Kind != knd.Char {
+		return ast.ErrExpect(a, knd.Char)
+	}
+	txt, err := cor.Unquote(a.Raw)
+	if err != nil {
+		return ast.ErrInvalid(a, knd.Char, err)
+	}
+	return x.Assign(Str(txt))
+}
+func (x *StrPrx) Assign(v Val) error {
+	if v == nil || v.Nil() {
+		return x.setNull()
+	}
+	s, err := ToStr(v)
+	if err != nil {
+		return err
+	}
+	x.elem().SetString(string(s))
+	return nil
+}
+func (x *StrPrx) value() string { return x.elem().String() }
+func (x *StrPrx) String() string {
+	if x.Nil() {
+		return "null"
+	}
+	return x.value()
+}
+func (x *StrPrx) MarshalJSON() ([]byte, error) {
+	if x.Nil() {
+		return []byte("null"), nil
+	}
+	return Str(x.value()).MarshalJSON()
+}
+func (x *StrPrx) UnmarshalJSON(b []byte) error { return x.unmarshal(b, x) }
+func (x *StrPrx) Print(p *bfr.P) error {
+	if x.Nil() {
+		return p.Fmt("null")
+	}
+	return Str(x.value()).Print(p)
+}
@@ -202,3 +202,83 @@ func testDefault(t *testing.T, c *PrxReg, mut Mut, ptr bool) {
 		testDefault(t, c, pmut, true)
 	}
 }
+
+type Color string
+
+func (Color) Consts() []typ.Const { return []typ.Const{typ.C("red", -1), typ.C("blue", -1)} }
+
+type Flags uint64
+
+func (Flags) Consts() []typ.Const { return []typ.Const{typ.C("a", 1), typ.C("b", 2)} }
+
+type Paint struct {
+	Color Color  `json:"color"`
+	Flags *Flags `json:"flags"`
+}
+
+func TestReflectConsts(t *testing.T) {
+	reg := &PrxReg{}
+	tests := []struct {
+		val  interface{}
+		want typ.Type
+	}{
+		{Color(""), typ.Enum("lit.Color", typ.C("red", -1), typ.C("blue", -1))},
+		{Flags(0), typ.Bits("lit.Flags", typ.C("a", 1), typ.C("b", 2))},
+		{Paint{}, typ.Obj("lit.Paint",
+			typ.P("color", typ.Enum("lit.Color", typ.C("red", -1), typ.C("blue", -1))),
+			typ.P("flags", typ.Opt(typ.Bits("lit.Flags", typ.C("a", 1), typ.C("b", 2)))),
+		)},
+	}
+	for _, test := range tests {
+		got, err := reg.Reflect(reflect.TypeOf(test.val))
+		if err != nil {
+			t.Errorf("reflect %T error: %v", test.val, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("reflect %T want %s got %s", test.val, test.want, got)
+		}
+	}
+}
+
+type Level uint8
+
+func (Level) Consts() []typ.Const { return []typ.Const{typ.C("low", 1), typ.C("high", 2)} }
+
+func TestProxyConsts(t *testing.T) {
+	reg := &PrxReg{}
+	tests := []struct {
+		ptr  interface{}
+		set  Val
+		want string
+	}{
+		{new(Color), Str("blue"), "'blue'"},
+		{new(*Color), Str("red"), "'red'"},
+		{new(Flags), Int(3), "3"},
+		{new(Level), Int(2), "2"},
+		{new(int8), Int(-3), "-3"},
+		{new(uint16), Int(7), "7"},
+	}
+	for _, test := range tests {
+		mut, err := reg.ProxyValue(reflect.ValueOf(test.ptr))
+		if err != nil {
+			t.Errorf("proxy %T error: %v", test.ptr, err)
+			continue
+		}
+		rt, err := reg.Reflect(reflect.TypeOf(test.ptr).Elem())
+		if err != nil {
+			t.Errorf("reflect %T error: %v", test.ptr, err)
+			continue
+		}
+		if !mut.Type().Equal(rt) {
+			t.Errorf("proxy %T want type %s got %s", test.ptr, rt, mut.Type())
+		}
+		if err = mut.Assign(test.set); err != nil {
+			t.Errorf("assign %T error: %v", test.ptr, err)
+			continue
+		}
+		if got := bfr.String(mut); got != test.want {
+			t.Errorf("proxy %T want %s got %s", test.ptr, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package lit
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/typ"
+)
+
+// DecodeQuery assigns the url values q to the keyr mut, like a proxied struct, or returns an error.
+//
+// Keys are paths into mut that use dots or slashes as separator, both 'filter.name' and 'tags/0'
+// are valid keys. Unlike in xelf paths a slash is a plain separator and does not select from all
+// list elements, keys must select exactly one value. Each value is converted to the type selected
+// by the key from the type of mut. Repeated keys are only valid for list types and map to list
+// elements. Bits values can be a number or const names separated by a pipe. Empty values are
+// ignored for all but char types, but still add zero elements to lists up to the key index.
+func DecodeQuery(q url.Values, mut Mut) error {
+	t := mut.Type()
+	if t.Kind&knd.Keyr == 0 {
+		return fmt.Errorf("decode query want keyr got %s", t)
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		path, err := queryPath(k)
+		if err != nil {
+			return fmt.Errorf("decode query key %q: %v", k, err)
+		}
+		pt, err := typ.SelectPath(t, path)
+		if err != nil {
+			return fmt.Errorf("decode query key %q: %v", k, err)
+		}
+		val, err := queryVal(q[k], pt)
+		if err != nil {
+			return fmt.Errorf("decode query key %q: %v", k, err)
+		}
+		// empty values still grow lists, so that zero elements keep their index
+		if err = growQuery(mut, t, path); err != nil {
+			return fmt.Errorf("decode query key %q: %v", k, err)
+		}
+		if val == nil {
+			continue
+		}
+		if _, err = CreatePath(mut, path, val); err != nil {
+			return fmt.Errorf("decode query key %q: %v", k, err)
+		}
+	}
+	return nil
+}
+
+// queryPath parses the query key k as path and replaces all slash with dot separators.
+func queryPath(k string) (cor.Path, error) {
+	path, err := cor.ParsePath(k)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range path {
+		if s.Sep() == '/' {
+			// keep the empty segment marker and only change the separator
+			path[i].Sel -= '/' - '.'
+		}
+	}
+	return path, nil
+}
+
+// growQuery appends zero elements to lists in mut along path so that all path indices are valid.
+func growQuery(mut Mut, t typ.Type, path cor.Path) error {
+	for i, s := range path {
+		if i == 0 || !s.IsIdx() || s.Idx < 0 {
+			continue
+		}
+		cur, err := SelectPath(mut, path[:i])
+		if err != nil {
+			// missing containers are created later
+			return nil
+		}
+		a, ok := cur.(Appender)
+		if !ok {
+			continue
+		}
+		et, err := typ.SelectPath(t, path[:i+1])
+		if err != nil {
+			return err
+		}
+		for a.Len() <= s.Idx {
+			if err = a.Append(Zero(et)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func queryVal(vs []string, t typ.Type) (Val, error) {
+	t = typ.Deopt(t)
+	if t.Kind&knd.List != 0 && t.Kind&^(knd.List|knd.None) == 0 {
+		et := typ.ContEl(t)
+		res := &List{Typ: t}
+		for _, s := range vs {
+			v, err := queryPrim(s, et)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				res.Vals = append(res.Vals, v)
+			}
+		}
+		return res, nil
+	}
+	if t.Kind&knd.Bits != 0 && t.Kind&^knd.Bits == 0 {
+		return queryBits(vs, t)
+	}
+	if len(vs) > 1 {
+		return nil, fmt.Errorf("repeated key for %s", t)
+	}
+	if len(vs) == 0 {
+		return nil, nil
+	}
+	return queryPrim(vs[0], t)
+}
+
+// queryPrim converts s to type t. It returns nil for empty strings of non-char types.
+func queryPrim(s string, t typ.Type) (Val, error) {
+	t = typ.Deopt(t)
+	k := t.Kind & knd.Any
+	if s == "" && (k&knd.Char == 0 || k&^knd.Char != 0) {
+		return nil, nil
+	}
+	switch {
+	case k == knd.Bool:
+		if s == "on" {
+			return Bool(true), nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return Bool(b), nil
+	case k == knd.Int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return Int(n), nil
+	case k == knd.Real || k == knd.Num:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return Real(n), nil
+	case k == knd.Bits:
+		return queryBits([]string{s}, t)
+	case k == knd.Enum:
+		cb, ok := t.Body.(*typ.ConstBody)
+		if ok {
+			idx := cb.FindKeyIndex(cor.Keyed(s))
+			if idx < 0 {
+				return nil, fmt.Errorf("no enum const %s in %s", s, t)
+			}
+			s = cb.Consts[idx].Name
+		}
+		return Str(s), nil
+	case k&knd.Char != 0 && k&^knd.Char == 0:
+		m := Zero(t)
+		if err := m.Assign(Str(s)); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case k&knd.Data == 0:
+		// any or other non-data types receive the plain string
+		return Str(s), nil
+	}
+	// other types like nested objects or dicts may be written as xelf literal
+	v, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	m := Zero(t)
+	if err = m.Assign(v); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// queryBits returns the bits value for numbers or const names separated by a pipe.
+func queryBits(vs []string, t typ.Type) (Val, error) {
+	cb, _ := t.Body.(*typ.ConstBody)
+	var res int64
+	for _, v := range vs {
+		for _, s := range strings.Split(v, "|") {
+			if s == "" {
+				continue
+			}
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				res |= n
+				continue
+			}
+			idx := -1
+			if cb != nil {
+				idx = cb.FindKeyIndex(cor.Keyed(s))
+			}
+			if idx < 0 {
+				return nil, fmt.Errorf("no bits const %s in %s", s, t)
+			}
+			res |= cb.Consts[idx].Val
+		}
+	}
+	return Int(res), nil
+}
+
+// EncodeQuery returns the url values for the keyr v or an error. It is the inverse of DecodeQuery.
+//
+// Nested keyr values use dotted keys and list elements use repeated keys. Lists with container
+// elements use slash separated index keys instead. Zero keyr fields are omitted, but all list
+// elements are encoded to keep their index. Zero container elements have an empty value.
+func EncodeQuery(v Val) (url.Values, error) {
+	if v == nil {
+		return nil, fmt.Errorf("encode query want keyr got nil")
+	}
+	if _, ok := v.(Keyr); !ok {
+		return nil, fmt.Errorf("encode query want keyr got %s", v.Type())
+	}
+	q := make(url.Values)
+	return q, encodeQuery(q, "", v)
+}
+
+func encodeQuery(q url.Values, key string, v Val) error {
+	switch w := v.(type) {
+	case Keyr:
+		return w.IterKey(func(k string, el Val) error {
+			if el == nil || el.Zero() {
+				return nil
+			}
+			if key != "" {
+				k = key + "." + k
+			}
+			return encodeQuery(q, k, el)
+		})
+	case Idxr:
+		return w.IterIdx(func(i int, el Val) error {
+			if el == nil {
+				el = Null{}
+			}
+			if el.Type().Kind&knd.Prim != 0 {
+				return encodeQuery(q, key, el)
+			}
+			k := fmt.Sprintf("%s/%d", key, i)
+			if el.Zero() {
+				q.Add(k, "")
+				return nil
+			}
+			return encodeQuery(q, k, el)
+		})
+	}
+	s, err := queryStr(v)
+	if err != nil {
+		return fmt.Errorf("encode query key %q: %v", key, err)
+	}
+	q.Add(key, s)
+	return nil
+}
+
+func queryStr(v Val) (string, error) {
+	k := v.Type().Kind & knd.Any
+	if k&knd.Char != 0 && k&^knd.Char == 0 {
+		s, err := ToStr(v)
+		return string(s), err
+	}
+	switch k {
+	case knd.Bool:
+		b, err := ToBool(v)
+		return strconv.FormatBool(bool(b)), err
+	case knd.Int, knd.Bits:
+		n, err := ToInt(v)
+		return strconv.FormatInt(int64(n), 10), err
+	case knd.Real, knd.Num:
+		n, err := ToReal(v)
+		return strconv.FormatFloat(float64(n), 'g', -1, 64), err
+	}
+	return v.String(), nil
+}
@@ -0,0 +1,150 @@
+package lit
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"xelf.org/xelf/cor"
+)
+
+type Filter struct {
+	Name  string `json:"name"`
+	Color Color  `json:"color"`
+	Flags Flags  `json:"flags"`
+}
+
+type Search struct {
+	Filter  Filter    `json:"filter"`
+	Tags    []string  `json:"tags"`
+	IDs     []int64   `json:"ids"`
+	Since   time.Time `json:"since"`
+	Span    Span      `json:"span"`
+	Ref     [16]byte  `json:"ref"`
+	Points  []Point   `json:"points"`
+	Limit   *int64    `json:"limit"`
+	Deleted bool      `json:"deleted"`
+}
+
+func TestQuery(t *testing.T) {
+	limit := int64(10)
+	since, _ := time.Parse(time.RFC3339, "2021-01-02T15:04:05Z")
+	ref := cor.NewUUID()
+	want := Search{
+		Filter:  Filter{Name: "x", Color: "blue", Flags: 3},
+		Tags:    []string{"y", "z"},
+		IDs:     []int64{1, 2},
+		Since:   since,
+		Span:    Span(90 * time.Minute),
+		Ref:     ref,
+		Points:  []Point{{X: 1, Y: 2}, {X: 3}},
+		Limit:   &limit,
+		Deleted: true,
+	}
+	q := url.Values{
+		"filter.name":  {"x"},
+		"filter.color": {"Blue"},
+		"filter.flags": {"a|b"},
+		"tags":         {"y", "z"},
+		"ids":          {"1", "2"},
+		"since":        {"2021-01-02T15:04:05Z"},
+		"span":         {"1:30:00"},
+		"ref":          {cor.FormatUUID(ref)},
+		"points/0.x":   {"1"},
+		"points/0.y":   {"2"},
+		"points/1":     {"{x:3}"},
+		"limit":        {"10"},
+		"deleted":      {"on"},
+	}
+	reg := &PrxReg{}
+	var got Search
+	err := DecodeQuery(q, MustProxy(reg, &got))
+	if err != nil {
+		t.Fatalf("decode query: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decode query want %+v got %+v", want, got)
+	}
+	enc, err := EncodeQuery(MustProxy(reg, &want))
+	if err != nil {
+		t.Fatalf("encode query: %v", err)
+	}
+	var back Search
+	err = DecodeQuery(enc, MustProxy(reg, &back))
+	if err != nil {
+		t.Fatalf("decode encoded query %s: %v", enc.Encode(), err)
+	}
+	if !reflect.DeepEqual(back, want) {
+		t.Errorf("decode encoded query %s want %+v got %+v", enc.Encode(), want, back)
+	}
+}
+
+func TestQueryKeyr(t *testing.T) {
+	v, err := Parse(`{a:1 b:{c:'d'} e:[1 2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := EncodeQuery(v)
+	if err != nil {
+		t.Fatalf("encode query: %v", err)
+	}
+	if got := q.Encode(); got != "a=1&b.c=d&e=1&e=2" {
+		t.Errorf("encode query got %s", got)
+	}
+}
+
+func TestQueryZeros(t *testing.T) {
+	type Zeros struct {
+		IDs    []int64 `json:"ids"`
+		Flags  []bool  `json:"flags"`
+		Points []Point `json:"points"`
+		Name   string  `json:"name"`
+	}
+	v, err := Parse(`{ids:[0 5 0] flags:[true false]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := EncodeQuery(v)
+	if err != nil {
+		t.Fatalf("encode query: %v", err)
+	}
+	if got := q.Encode(); got != "flags=true&flags=false&ids=0&ids=5&ids=0" {
+		t.Errorf("encode query got %s", got)
+	}
+	want := Zeros{IDs: []int64{0, 5, 0}, Flags: []bool{true, false}, Points: []Point{{X: 1}, {}}}
+	reg := &PrxReg{}
+	enc, err := EncodeQuery(MustProxy(reg, &want))
+	if err != nil {
+		t.Fatalf("encode query: %v", err)
+	}
+	var got Zeros
+	if err = DecodeQuery(enc, MustProxy(reg, &got)); err != nil {
+		t.Fatalf("decode encoded query %s: %v", enc.Encode(), err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decode encoded query %s want %+v got %+v", enc.Encode(), want, got)
+	}
+	if _, err = EncodeQuery(nil); err == nil {
+		t.Errorf("encode query nil want error")
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []url.Values{
+		{"missing": {"1"}},
+		{"limit": {"x"}},
+		{"limit": {"1", "2"}},
+		{"filter.color": {"green"}},
+		{"filter.flags": {"c"}},
+		{"since": {"yesterday"}},
+		{"ids/x": {"1"}},
+	}
+	reg := &PrxReg{}
+	for _, q := range tests {
+		var s Search
+		if err := DecodeQuery(q, MustProxy(reg, &s)); err == nil {
+			t.Errorf("decode query %s want error", q.Encode())
+		}
+	}
+}
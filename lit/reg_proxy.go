@@ -31,7 +31,19 @@ func (c *PrxReg) ProxyValue(ptr reflect.Value) (mut Mut, err error) {
 	if ok {
 		return prx.NewWith(org), nil
 	}
-	switch et.Kind() {
+	kind := et.Kind()
+	if isConsts(et) {
+		// const types are proxied with the reflected enum or bits type
+		switch ct := reflectConsts(et); kind {
+		case reflect.String:
+			mut = &StrPrx{newProxy(c, ct, org)}
+		case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+			reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+			mut = &IntPrx{newProxy(c, ct, org)}
+		}
+		kind = reflect.Invalid
+	}
+	switch kind {
 	case reflect.Bool:
 		if v, ok := toRef(ptrBoolMut, ptr, org); ok {
 			return optPrx(v.Interface().(*BoolMut), org, opt, null)
@@ -46,7 +58,8 @@ func (c *PrxReg) ProxyValue(ptr reflect.Value) (mut Mut, err error) {
 			return optPrx(v.Interface().(*IntMut), org, opt, null)
 		}
 		fallthrough
-	case reflect.Int, reflect.Int32, reflect.Int16, reflect.Uint64, reflect.Uint32, reflect.Uint16:
+	case reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
 		mut = &IntPrx{newProxy(c, typ.Int, org)}
 	case reflect.Float64:
 		if pt == ptrNumMut {
@@ -49,6 +49,9 @@ func (pr *PrxReg) ReflectStruct(t reflect.Type) (nfo typInfo, err error) {
 	return nfo, err
 }
 func reflectSimple(t reflect.Type) typ.Type {
+	if isConsts(t) {
+		return typ.Void
+	}
 	// first switch on the primitives that do not need convertible to type check
 	// to avoid the extra map lookup
 	switch t.Kind() {
@@ -59,11 +62,11 @@ func reflectSimple(t reflect.Type) typ.Type {
 			break
 		}
 		fallthrough
-	case reflect.Int, reflect.Int32:
+	case reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8:
 		return typ.Int
 	case reflect.Uint64:
 		fallthrough
-	case reflect.Uint, reflect.Uint32:
+	case reflect.Uint, reflect.Uint32, reflect.Uint16, reflect.Uint8:
 		return typ.Int
 	case reflect.Float32, reflect.Float64:
 		return typ.Real
@@ -94,13 +97,24 @@ func (pr *PrxReg) reflectTypeRest(t reflect.Type, ptr bool, s *tstack) (res typ.
 	// to avoid the extra map lookup
 	// now lets cache and lookup all other types that require more involved type checks
 	var pm *params
+	depth, sel := len(s.stack), s.sel
+	s.sel = 0
 	switch t.Kind() {
 	case reflect.Int64:
+		if isConsts(t) {
+			res = reflectConsts(t)
+			break
+		}
 		if t != typInt64 && isRef(t, ptrSecs.Elem()) {
 			res = typ.Span
 			break
 		}
 		res = typ.Int
+	case reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.String:
+		if isConsts(t) {
+			res = reflectConsts(t)
+		}
 	case reflect.Struct:
 		if isRef(t, ptrTimeMut.Elem()) {
 			res = typ.Time
@@ -151,7 +165,13 @@ func (pr *PrxReg) reflectTypeRest(t reflect.Type, ptr bool, s *tstack) (res typ.
 	if res.Zero() {
 		return res, fmt.Errorf("cannot reflect type of %s", t)
 	}
-	pr.setParam(t, typInfo{res, pm})
+	// types that select an enclosing struct are only valid in this context and not cached
+	if s.sel == 0 || s.sel > depth {
+		pr.setParam(t, typInfo{res, pm})
+	}
+	if sel != 0 && (s.sel == 0 || sel < s.sel) {
+		s.sel = sel
+	}
 	if ptr {
 		res = typ.Opt(res)
 	}
@@ -167,15 +187,34 @@ func (pr *PrxReg) reflectStruct(t reflect.Type, s *tstack) (typ.Type, *params, e
 	if err != nil {
 		return typ.Void, nil, err
 	}
-	tn := t.Name()
-	if tn != "" {
-		if cor.IsCased(tn) {
-			tn = t.String()
-		} else {
-			tn = ""
-		}
+	return typ.Type{Kind: knd.Obj, Ref: typeRef(t), Body: &typ.ParamBody{Params: pm.ps}}, &pm, nil
+}
+
+// typeRef returns the qualified type name for exported named go types or an empty string.
+func typeRef(t reflect.Type) string {
+	if tn := t.Name(); tn != "" && cor.IsCased(tn) {
+		return t.String()
 	}
-	return typ.Type{Kind: knd.Obj, Ref: tn, Body: &typ.ParamBody{Params: pm.ps}}, &pm, nil
+	return ""
+}
+
+// Consts is the interface for named go types that represent xelf enum or bits types.
+// String types are reflected as enum types and integer types as bits types.
+type Consts interface {
+	Consts() []typ.Const
+}
+
+func isConsts(t reflect.Type) bool {
+	return t.NumMethod() > 0 && t.Implements(ptrConsts.Elem())
+}
+
+func reflectConsts(t reflect.Type) typ.Type {
+	cs := reflect.Zero(t).Interface().(Consts).Consts()
+	k := knd.Bits
+	if t.Kind() == reflect.String {
+		k = knd.Enum
+	}
+	return typ.Type{Kind: k, Ref: typeRef(t), Body: &typ.ConstBody{Consts: cs}}
 }
 func (pr *PrxReg) reflectFields(t reflect.Type, s *tstack) (pm params, _ error) {
 	n := t.NumField()
@@ -252,6 +291,8 @@ func (pr *PrxReg) addEmbed(pm *params, t reflect.Type, s *tstack, idx []int) (bo
 
 type tstack struct {
 	stack []reflect.Type
+	// sel is one more than the lowest stack index selected or zero
+	sel int
 }
 
 func (ts *tstack) add(t reflect.Type) string {
@@ -259,6 +300,9 @@ func (ts *tstack) add(t reflect.Type) string {
 		if ts.stack[i] != t {
 			continue
 		}
+		if ts.sel == 0 || i < ts.sel-1 {
+			ts.sel = i + 1
+		}
 		var b strings.Builder
 		for n := i; n < len(ts.stack); n++ {
 			b.WriteByte('.')
@@ -276,11 +320,12 @@ func isRef(t reflect.Type, ref reflect.Type) bool {
 }
 
 var (
-	ptrMut   = reflect.TypeOf((*Mut)(nil))
-	ptrPrx   = reflect.TypeOf((*Prx)(nil))
-	ptrType  = reflect.TypeOf((*typ.Type)(nil))
-	ptrList  = reflect.TypeOf((*List)(nil))
-	ptrDict  = reflect.TypeOf((*Dict)(nil))
-	ptrSecs  = reflect.TypeOf((*interface{ Seconds() float64 })(nil))
-	typInt64 = reflect.TypeOf(int64(0))
+	ptrMut    = reflect.TypeOf((*Mut)(nil))
+	ptrPrx    = reflect.TypeOf((*Prx)(nil))
+	ptrConsts = reflect.TypeOf((*Consts)(nil))
+	ptrType   = reflect.TypeOf((*typ.Type)(nil))
+	ptrList   = reflect.TypeOf((*List)(nil))
+	ptrDict   = reflect.TypeOf((*Dict)(nil))
+	ptrSecs   = reflect.TypeOf((*interface{ Seconds() float64 })(nil))
+	typInt64  = reflect.TypeOf(int64(0))
 )
@@ -1,8 +1,10 @@
 package mod
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"xelf.org/xelf/cor"
 	"xelf.org/xelf/exp"
@@ -16,6 +18,10 @@ import (
 type LoaderEnv struct {
 	Par     exp.Env
 	Loaders []Loader
+	// Lock optionally records and verifies all loaded module sources.
+	Lock  *Lock
+	mu    sync.Mutex
+	tests []*TestGroup
 }
 
 // NewLoaderEnv create a new module loader environment with the given parent env and loader.
@@ -35,10 +41,12 @@ func FindLoaderEnv(env exp.Env) *LoaderEnv {
 func (le *LoaderEnv) LoadFile(prog *exp.Prog, loc *Loc) (f *File, err error) {
 	base := ParseLoc(prog.File.URL)
 	var src *Src
+	var rel bool
 	for _, l := range le.Loaders {
 		src, err = l.LoadSrc(loc, base)
 		if err != nil {
-			if err == ErrFileNotFound {
+			if err == ErrFileNotFound || err == ErrRelPath {
+				rel = rel || err == ErrRelPath
 				continue
 			}
 			return nil, fmt.Errorf("module source load error for %s:\n%v", loc.URL, err)
@@ -48,10 +56,15 @@ func (le *LoaderEnv) LoadFile(prog *exp.Prog, loc *Loc) (f *File, err error) {
 		} else if f = prog.Files[src.URL]; f != nil {
 			return f, nil
 		}
+		if le.Lock != nil {
+			if err = le.Lock.Check(src, loc.Version()); err != nil {
+				return nil, err
+			}
+		}
 		if prog.Birth == nil {
 			prog.Birth = make(map[string]struct{})
 		} else if _, ok := prog.Birth[src.URL]; ok {
-			return nil, fmt.Errorf("module load recursion detected for %s", src.URL)
+			return nil, &CycleError{URL: src.URL}
 		}
 		prog.Birth[src.URL] = struct{}{}
 		if src.Setup != nil {
@@ -79,8 +92,14 @@ func (le *LoaderEnv) LoadFile(prog *exp.Prog, loc *Loc) (f *File, err error) {
 		prog.Files[src.URL] = f
 		return f, nil
 	}
-	if err == nil {
-		err = ErrFileNotFound
+	if err == nil || err == ErrFileNotFound || err == ErrRelPath {
+		if err = ErrFileNotFound; rel {
+			err = ErrRelPath
+		}
+	}
+	var ce *CycleError
+	if errors.As(err, &ce) {
+		return nil, ce
 	}
 	return nil, fmt.Errorf("module load failed for %s:\n%v", loc, err)
 }
@@ -96,14 +115,40 @@ func (le *LoaderEnv) Lookup(s *exp.Sym, p cor.Path, eval bool) (lit.Val, error)
 		return exp.NewSpecRef(Import), nil
 	case "export":
 		return exp.NewSpecRef(Export), nil
+	case "test":
+		return exp.NewSpecRef(Test), nil
 	}
-	return le.Par.Lookup(s, p, eval)
+	v, err := le.Par.Lookup(s, p, eval)
+	if err == exp.ErrSymNotFound {
+		// names used unqualified by imports do not shadow builtins
+		if prog := exp.FindProg(s.Env); prog != nil {
+			if u, err := lookupUse(prog, p); err == nil {
+				return u, nil
+			}
+		}
+	}
+	return v, err
+}
+
+// lookupUse looks up an unqualified declaration used by a module reference of the program file.
+func lookupUse(p *exp.Prog, path cor.Path) (lit.Val, error) {
+	if len(path) == 0 || path[0].Sep() != 0 {
+		return nil, exp.ErrSymNotFound
+	}
+	for _, m := range p.File.Refs {
+		if m.Uses(path[0].Key) {
+			return exp.LookupMod(p, m.Key(), path)
+		}
+	}
+	return nil, exp.ErrSymNotFound
 }
 
 // ModEnv encapsulates a module environment.
+// Declarations with a leading underscore are private to the module and not published.
 type ModEnv struct {
-	Par exp.Env
-	Mod *Mod
+	Par  exp.Env
+	Mod  *Mod
+	Priv *lit.Obj
 }
 
 func FindModEnv(env exp.Env) *ModEnv {
@@ -115,9 +160,11 @@ func FindModEnv(env exp.Env) *ModEnv {
 	return nil
 }
 func NewModEnv(par exp.Env, file *File) *ModEnv {
-	return &ModEnv{Par: par, Mod: &Mod{File: file, Decl: &lit.Obj{
-		Typ: typ.Type{Kind: knd.Obj, Body: &typ.ParamBody{}},
-	}}}
+	return &ModEnv{Par: par, Mod: &Mod{File: file, Decl: newDecl()}, Priv: newDecl()}
+}
+
+func newDecl() *lit.Obj {
+	return &lit.Obj{Typ: typ.Type{Kind: knd.Obj, Body: &typ.ParamBody{}}}
 }
 
 func (e *ModEnv) Parent() exp.Env { return e.Par }
@@ -133,15 +180,19 @@ func (e *ModEnv) AddDecl(name string, v lit.Val) error {
 		if name == "" || !cor.IsName(name) {
 			return fmt.Errorf("invalid module declaration name %q", name)
 		}
-		p := typ.P(name, v.Type())
-		pb := m.Decl.Typ.Body.(*typ.ParamBody)
-		for _, o := range pb.Params {
-			if p.Key == o.Key {
-				return fmt.Errorf("module declaration name %q is not unique", name)
+		if m.HasDecl(name) || e.Priv != nil && (&Mod{Decl: e.Priv}).HasDecl(name) {
+			return fmt.Errorf("module declaration name %q is not unique", name)
+		}
+		decl := m.Decl
+		if name[0] == '_' {
+			if e.Priv == nil {
+				e.Priv = newDecl()
 			}
+			decl = e.Priv
 		}
-		pb.Params = append(pb.Params, p)
-		m.Decl.Vals = append(m.Decl.Vals, v)
+		pb := decl.Typ.Body.(*typ.ParamBody)
+		pb.Params = append(pb.Params, typ.P(name, v.Type()))
+		decl.Vals = append(decl.Vals, v)
 	}
 	return nil
 }
@@ -168,6 +219,12 @@ func (e *ModEnv) Lookup(s *exp.Sym, path cor.Path, eval bool) (lit.Val, error) {
 		if err == nil && v != nil {
 			return v, nil
 		}
+		if e.Priv != nil && len(p) > 0 && strings.HasPrefix(p[0].Key, "_") {
+			v, err = lit.SelectPath(e.Priv, p)
+			if err == nil && v != nil {
+				return v, nil
+			}
+		}
 		if len(p) != len(path) {
 			return nil, exp.ErrSymNotFound
 		}
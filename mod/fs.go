@@ -1,15 +1,14 @@
 package mod
 
 import (
-	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"strings"
-
-	"xelf.org/xelf/ast"
+	"sync"
 )
 
+// FileMods returns a module loader for file locations in the given root directories.
 func FileMods(roots ...string) *FSMods {
 	fm := &FSMods{
 		Roots: make([]*PathFS, 0, len(roots)),
@@ -22,6 +21,18 @@ func FileMods(roots ...string) *FSMods {
 	return fm
 }
 
+// MountMods returns a module loader for locations with protocol proto in the file system fsys.
+// Module paths without protocol are also looked up in fsys. It can be used to mount an embed.FS
+// with bundled modules.
+func MountMods(proto string, fsys fs.FS) *FSMods {
+	return &FSMods{
+		Roots: []*PathFS{{FS: fsys}},
+		Proto: proto,
+		Ext:   []string{".xelf"},
+		Index: []string{"mod.xelf"},
+	}
+}
+
 type PathFS struct {
 	Path  string
 	FS    fs.FS
@@ -31,17 +42,29 @@ type PathFS struct {
 
 type FSMods struct {
 	Roots []*PathFS
+	// Proto is the location protocol of this loader, it defaults to file.
+	Proto string
 	Ext   []string
 	Index []string
 
 	Log   func(root, path string)
+	mu    sync.Mutex
 	local map[string]*PathFS
 }
 
+func (fm *FSMods) proto() string {
+	if fm.Proto == "" {
+		return "file"
+	}
+	return fm.Proto
+}
+
 func (fm *FSMods) LoadSrc(raw, base *Loc) (*Src, error) {
-	if proto := raw.Proto(); proto != "" && proto != "file" {
+	if proto := raw.Proto(); proto != "" && proto != fm.proto() {
 		return nil, ErrFileNotFound
 	}
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
 	p, roots := raw.Path(), fm.Roots
 	if strings.HasPrefix(p, "./") {
 		p = p[2:]
@@ -62,12 +85,61 @@ func (fm *FSMods) LoadSrc(raw, base *Loc) (*Src, error) {
 	}
 	return nil, ErrFileNotFound
 }
+
+// Forget removes the cached sources with the given urls and all cached misses from all roots.
+func (fm *FSMods) Forget(urls ...string) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.eachRoot(func(r *PathFS) {
+		for k, src := range r.cache {
+			if src == nil || containsStr(urls, src.URL) {
+				delete(r.cache, k)
+			}
+		}
+	})
+}
+
+// fileSrc is a cached source with the file system it was read from.
+type fileSrc struct {
+	FS fs.FS
+	*Src
+}
+
+// cached returns all cached sources.
+func (fm *FSMods) cached() (res []fileSrc) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	seen := make(map[*Src]bool)
+	fm.eachRoot(func(r *PathFS) {
+		for _, src := range r.cache {
+			if src != nil && !seen[src] {
+				seen[src] = true
+				res = append(res, fileSrc{r.FS, src})
+			}
+		}
+	})
+	return res
+}
+
+func (fm *FSMods) eachRoot(f func(*PathFS)) {
+	for _, r := range fm.Roots {
+		f(r)
+	}
+	for _, r := range fm.local {
+		f(r)
+	}
+}
+
 func (fm *FSMods) relRoot(p string, base *Loc) (*PathFS, error) {
-	bp := base.Path()
-	if pr := base.Proto(); base == nil || pr != "" && pr != "file" {
-		return nil, fmt.Errorf("relative mod path not allowed here")
+	if pr := base.Proto(); base == nil || pr != "" && pr != fm.proto() {
+		// other loaders may handle relative paths for this base
+		return nil, ErrRelPath
 	}
-	rel := path.Dir(bp)
+	return fm.dirRoot(path.Dir(base.Path()))
+}
+
+// dirRoot returns a root for the directory rel, that is either part of a root or an os directory.
+func (fm *FSMods) dirRoot(rel string) (*PathFS, error) {
 	if fm.local == nil {
 		fm.local = make(map[string]*PathFS)
 	} else if r := fm.local[rel]; r != nil {
@@ -78,16 +150,19 @@ func (fm *FSMods) relRoot(p string, base *Loc) (*PathFS, error) {
 		if rel == rp {
 			return r, nil
 		}
-		if strings.HasPrefix(rel, rp) {
+		if rp == "." && fs.ValidPath(rel) || strings.HasPrefix(rel, rp+"/") {
 			if r.cache == nil {
 				r.cache = make(map[string]*Src)
 			}
 			tmp := *r
-			tmp.Rel = rel[len(rp)+1:]
+			tmp.Rel = strings.TrimPrefix(rel, rp+"/")
 			fm.local[rel] = &tmp
 			return &tmp, nil
 		}
 	}
+	if fm.proto() != "file" {
+		return nil, ErrFileNotFound
+	}
 	r := &PathFS{Path: rel, FS: os.DirFS(rel)}
 	fm.local[rel] = r
 	return r, nil
@@ -106,38 +181,7 @@ func (fm *FSMods) try(r *PathFS, part string) (*Src, error) {
 		}
 		return s, nil
 	}
-	// always try sym as is
-	fi, err := fs.Stat(r.FS, p)
-	var found string
-	if err != nil {
-		for _, ext := range fm.Ext {
-			pp := p + ext
-			_, err := fs.Stat(r.FS, pp)
-			if err == nil {
-				found = pp
-				break
-			}
-		}
-	} else if fi.IsDir() {
-		for _, name := range fm.Index {
-			pp := path.Join(p, name)
-			_, err := fs.Stat(r.FS, pp)
-			if err == nil {
-				found = pp
-				break
-			}
-		}
-		for _, ext := range fm.Ext {
-			pp := path.Join(p, fi.Name()+ext)
-			_, err := fs.Stat(r.FS, pp)
-			if err == nil {
-				found = pp
-				break
-			}
-		}
-	} else {
-		found = p
-	}
+	found := findFile(r.FS, p, fm.Ext, fm.Index)
 	if found == "" {
 		r.cache[p] = nil
 		return nil, ErrFileNotFound
@@ -157,14 +201,59 @@ func (fm *FSMods) try(r *PathFS, part string) (*Src, error) {
 	}
 	defer ff.Close()
 	full := path.Join(r.Path, found)
-	as, err := ast.ReadAll(ff, full)
+	as, hash, err := readSrc(ff, full)
 	if err != nil {
 		return nil, err
 	}
-	src := &Src{Rel: found, Loc: Loc{URL: "file:" + full}, Raw: as}
+	src := &Src{Rel: found, Loc: Loc{URL: fm.proto() + ":" + full}, Hash: hash, Raw: as}
 	r.cache[p] = src
 	if p != found {
 		r.cache[found] = src
 	}
 	return src, nil
 }
+
+// findFile returns the file path in fsys for module path p or an empty string.
+// It tries the path as is, with any of the extensions exts, and for directories with any of the
+// index file names or the directory name with extension.
+func findFile(fsys fs.FS, p string, exts, index []string) string {
+	// always try sym as is
+	fi, err := fs.Stat(fsys, p)
+	if err != nil {
+		for _, ext := range exts {
+			pp := p + ext
+			if _, err := fs.Stat(fsys, pp); err == nil {
+				return pp
+			}
+		}
+		return ""
+	}
+	if !fi.IsDir() {
+		return p
+	}
+	var found string
+	for _, name := range index {
+		pp := path.Join(p, name)
+		if _, err := fs.Stat(fsys, pp); err == nil {
+			found = pp
+			break
+		}
+	}
+	for _, ext := range exts {
+		pp := path.Join(p, fi.Name()+ext)
+		if _, err := fs.Stat(fsys, pp); err == nil {
+			found = pp
+			break
+		}
+	}
+	return found
+}
+
+func containsStr(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,15 @@
 package mod
 
 import (
+	"archive/zip"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"xelf.org/xelf/exp"
 	"xelf.org/xelf/lib"
@@ -88,3 +93,193 @@ func TestFSMods(t *testing.T) {
 		t.Errorf("reads got %s want %s", reads, want)
 	}
 }
+
+func TestMountMods(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"liba.xelf", "libb.xelf", "mod.xelf"} {
+		raw, err := os.ReadFile("testdata/lib/name.org/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys["name.org/"+name] = &fstest.MapFile{Data: raw}
+	}
+	env := NewLoaderEnv(exp.Builtins(lib.Std), MountMods("lib", fsys))
+	tests := []struct {
+		raw   string
+		local []string
+		want  string
+	}{
+		{"(import 'name.org/liba') liba.name", []string{
+			"lib:name.org/liba.xelf#liba",
+		}, `"liba"`},
+		{"(import 'lib:name.org/libb') libb.name", []string{
+			"lib:name.org/libb.xelf#libb",
+		}, `"libb using liba"`},
+		{"(import 'name.org') prod.name", []string{
+			"lib:name.org/liba.xelf#liba",
+			"lib:name.org/libb.xelf#libb",
+			"lib:name.org/mod.xelf#prod",
+		}, `"my product with liba and libb using liba"`},
+	}
+	for _, test := range tests {
+		testModRun(t, env, "", test.raw, test.local, test.want)
+	}
+	p := exp.NewProg(env)
+	p.File.URL = "zip:other.zip#main.xelf"
+	_, err := p.RunStr("(import './name.org/liba') liba.name", nil)
+	if err == nil || !strings.Contains(err.Error(), ErrRelPath.Error()) {
+		t.Errorf("want relative path error got %v", err)
+	}
+}
+
+func TestZipMods(t *testing.T) {
+	arc := filepath.Join(t.TempDir(), "lib.zip")
+	f, err := os.Create(arc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"liba.xelf", "libb.xelf", "mod.xelf"} {
+		raw, err := os.ReadFile("testdata/lib/name.org/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := zw.Create("name.org/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(raw)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	zm := NewZipMods()
+	defer zm.Close()
+	var reads int
+	zm.Log = func(string, string) { reads++ }
+	env := NewLoaderEnv(exp.Builtins(lib.Std), zm, FileMods())
+	pre := "zip:" + arc + "#"
+	tests := []struct {
+		raw   string
+		local []string
+		want  string
+	}{
+		{"(import 'zip:" + arc + "#name.org/liba') liba.name", []string{
+			pre + "name.org/liba.xelf#liba",
+		}, `"liba"`},
+		{"(import 'zip:" + arc + "#name.org/libb') libb.name", []string{
+			pre + "name.org/libb.xelf#libb",
+		}, `"libb using liba"`},
+		{"(import 'zip:" + arc + "#name.org') prod.name", []string{
+			pre + "name.org/liba.xelf#liba",
+			pre + "name.org/libb.xelf#libb",
+			pre + "name.org/mod.xelf#prod",
+		}, `"my product with liba and libb using liba"`},
+		{"(import 'zip:" + arc + "#name.org#libb') libb.name", []string{
+			pre + "name.org/libb.xelf#libb",
+		}, `"libb using liba"`},
+	}
+	for _, test := range tests {
+		testModRun(t, env, "testdata/", test.raw, test.local, test.want)
+	}
+	if reads != 3 {
+		t.Errorf("want 3 archive reads got %d", reads)
+	}
+}
+
+func testModRun(t *testing.T, env exp.Env, url, raw string, want []string, res string) {
+	t.Helper()
+	x, err := exp.Parse(raw)
+	if err != nil {
+		t.Errorf("%s parse failed: %v", raw, err)
+		return
+	}
+	p := exp.NewProg(env)
+	p.File.URL = url
+	v, err := p.Run(x, nil)
+	if err != nil {
+		t.Errorf("%s run failed: %v", raw, err)
+		return
+	}
+	var local []string
+	for _, m := range p.File.Refs {
+		local = append(local, m.File.URL+"#"+m.Name)
+	}
+	sort.Strings(local)
+	if !reflect.DeepEqual(local, want) {
+		t.Errorf("%s got file mods %s want %s", raw, local, want)
+	}
+	got, _ := v.MarshalJSON()
+	if string(got) != res {
+		t.Errorf("%s got result %s want %s", raw, got, res)
+	}
+}
+
+func TestFSModsRoots(t *testing.T) {
+	fm := FileMods("testdata/lib")
+	abs, err := filepath.Abs("testdata/foo.xelf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"file:" + filepath.ToSlash(abs), "file:testdata/foo.xelf"} {
+		_, err := fm.LoadSrc(ParseLoc(raw), nil)
+		if err != ErrFileNotFound {
+			t.Errorf("%s want file not found got %v", raw, err)
+		}
+	}
+}
+
+func TestVersionMods(t *testing.T) {
+	vm := NewVersionMods(map[string]string{
+		"company.com/prod@v1": "file:testdata/ver/v1",
+		"company.com/prod@v2": "file:testdata/ver/v2",
+	})
+	env := NewLoaderEnv(exp.Builtins(lib.Std), vm, FileMods())
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"(import 'company.com/prod@v2') prod.Prod", "<obj@prod.Prod>"},
+		{"(import p1:'company.com/prod@v1' p2:'company.com/prod@v2#prod') ([]+ p1.Prod p2.Prod)",
+			"[<obj@p1.Prod> <obj@p2.Prod>]"},
+		{"(import p1:'company.com/prod@v1' p2:'company.com/prod@v2') " +
+			"([]+ (p1.Prod id:1) (p2.Prod id:2 price:3))",
+			"[{id:1 name:''} {id:2 name:'' price:3}]"},
+	}
+	for _, test := range tests {
+		x, err := exp.Parse(test.raw)
+		if err != nil {
+			t.Errorf("%s parse failed: %v", test.raw, err)
+			continue
+		}
+		res, err := exp.NewProg(env).Run(x, nil)
+		if err != nil {
+			t.Errorf("%s run failed: %v", test.raw, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("%s got %s want %s", test.raw, got, test.want)
+		}
+	}
+	p := exp.NewProg(env)
+	_, err := p.RunStr("(import p1:'company.com/prod@v1' p2:'company.com/prod@v2')", nil)
+	if err != nil {
+		t.Fatalf("import versions: %v", err)
+	}
+	var got []string
+	for _, m := range p.File.Refs {
+		got = append(got, fmt.Sprintf("%s %s %s", m.Key(), m.Version, m.File.URL))
+	}
+	want := []string{
+		"p1 v1 file:testdata/ver/v1/mod.xelf",
+		"p2 v2 file:testdata/ver/v2/mod.xelf",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want refs %q got %q", want, got)
+	}
+	_, err = exp.NewProg(env).RunStr("(import 'company.com/prod@v1' 'company.com/prod@v2')", nil)
+	if err == nil {
+		t.Errorf("want error for conflicting module names")
+	}
+}
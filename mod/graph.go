@@ -0,0 +1,170 @@
+package mod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/typ"
+)
+
+// Graph is the module import graph of a program.
+type Graph struct {
+	// Files holds the program file followed by all loaded files sorted by url.
+	Files []GraphFile `json:"files"`
+}
+
+// GraphFile is a file in the module graph with all its module references.
+type GraphFile struct {
+	URL  string     `json:"url"`
+	Refs []GraphRef `json:"refs"`
+}
+
+// GraphRef is a module reference of a file. Modules declared in the file have the file url.
+// Public references are declared or re-exported by the file.
+type GraphRef struct {
+	Key     string   `json:"key"`
+	Name    string   `json:"name"`
+	Path    string   `json:"path,omitempty"`
+	Version string   `json:"version,omitempty"`
+	URL     string   `json:"url"`
+	Pub     bool     `json:"pub,omitempty"`
+	Decls   []string `json:"decls"`
+}
+
+// NewGraph returns the module import graph of program p.
+func NewGraph(p *exp.Prog) *Graph {
+	g := &Graph{Files: []GraphFile{graphFile(&p.File)}}
+	urls := make([]string, 0, len(p.Files))
+	for url := range p.Files {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		g.Files = append(g.Files, graphFile(p.Files[url]))
+	}
+	return g
+}
+
+// File returns the graph file with url or nil.
+func (g *Graph) File(url string) *GraphFile {
+	for i := range g.Files {
+		if g.Files[i].URL == url {
+			return &g.Files[i]
+		}
+	}
+	return nil
+}
+
+// Deps returns the sorted urls of all files that file url imports modules from.
+func (g *Graph) Deps(url string) (res []string) {
+	f := g.File(url)
+	if f == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, r := range f.Refs {
+		if r.URL != url && !seen[r.URL] {
+			seen[r.URL] = true
+			res = append(res, r.URL)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// String renders the graph as plain text with a line for each file and module reference.
+func (g *Graph) String() string {
+	var b strings.Builder
+	for _, f := range g.Files {
+		url := f.URL
+		if url == "" {
+			url = "<prog>"
+		}
+		b.WriteString(url)
+		b.WriteByte('\n')
+		for _, r := range f.Refs {
+			fmt.Fprintf(&b, "\t%s", r.Key)
+			if r.Key != r.Name {
+				fmt.Fprintf(&b, "=%s", r.Name)
+			}
+			if r.URL != f.URL {
+				fmt.Fprintf(&b, " from %s", r.URL)
+			}
+			if r.Pub {
+				b.WriteString(" pub")
+			}
+			if len(r.Decls) > 0 {
+				fmt.Fprintf(&b, " [%s]", strings.Join(r.Decls, " "))
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func graphFile(f *exp.File) GraphFile {
+	res := GraphFile{URL: f.URL, Refs: make([]GraphRef, 0, len(f.Refs))}
+	for _, m := range f.Refs {
+		r := GraphRef{Key: m.Key(), Name: m.Name, Path: m.Path, Version: m.Version, Pub: m.Pub}
+		if m.Mod == nil {
+			res.Refs = append(res.Refs, r)
+			continue
+		}
+		if m.File != nil {
+			r.URL = m.File.URL
+		}
+		if m.Decl != nil {
+			if pb, ok := m.Decl.Typ.Body.(*typ.ParamBody); ok {
+				for _, p := range pb.Params {
+					r.Decls = append(r.Decls, p.Key)
+				}
+			}
+		}
+		res.Refs = append(res.Refs, r)
+	}
+	return res
+}
+
+// CycleError is returned for recursive module loads and holds the import steps of the cycle.
+type CycleError struct {
+	// URL is the url of the file that was loaded recursively.
+	URL string
+	// Steps holds the imports in load order starting at the file with url.
+	Steps []CycleStep
+	done  bool
+}
+
+// CycleStep is an import in a module cycle.
+type CycleStep struct {
+	// File is the url of the importing file.
+	File string
+	// Path is the imported module path.
+	Path string
+	// Src is the source position of the import path.
+	Src ast.Src
+}
+
+func (e *CycleError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "module load recursion detected for %s", e.URL)
+	for _, s := range e.Steps {
+		pos := s.Src.String()
+		if s.Src.Doc == nil || s.Src.Name == "" {
+			pos = s.File + pos
+		}
+		fmt.Fprintf(&b, "\n\t%s: import %s", pos, s.Path)
+	}
+	return b.String()
+}
+
+// addStep prepends an import step to an incomplete cycle.
+func (e *CycleError) addStep(file, path string, src ast.Src) {
+	if e.done {
+		return
+	}
+	e.Steps = append([]CycleStep{{File: file, Path: path, Src: src}}, e.Steps...)
+	e.done = file == e.URL
+}
@@ -0,0 +1,57 @@
+package mod
+
+import (
+	"errors"
+	"testing"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+)
+
+func TestGraph(t *testing.T) {
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods("testdata/lib"))
+	p := exp.NewProg(env)
+	_, err := p.RunStr("(import 'name.org')", nil)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	g := NewGraph(p)
+	want := `<prog>
+	liba from file:testdata/lib/name.org/liba.xelf [name]
+	libb from file:testdata/lib/name.org/libb.xelf [name]
+	prod from file:testdata/lib/name.org/mod.xelf [name]
+file:testdata/lib/name.org/liba.xelf
+	liba pub [name]
+file:testdata/lib/name.org/libb.xelf
+	liba from file:testdata/lib/name.org/liba.xelf [name]
+	libb pub [name]
+file:testdata/lib/name.org/mod.xelf
+	liba from file:testdata/lib/name.org/liba.xelf pub [name]
+	libb from file:testdata/lib/name.org/libb.xelf pub [name]
+	prod pub [name]
+`
+	if got := g.String(); got != want {
+		t.Errorf("graph got:\n%s\nwant:\n%s", got, want)
+	}
+	deps := g.Deps("file:testdata/lib/name.org/mod.xelf")
+	if len(deps) != 2 || deps[0] != "file:testdata/lib/name.org/liba.xelf" {
+		t.Errorf("unexpected deps %v", deps)
+	}
+}
+
+func TestCycleError(t *testing.T) {
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods())
+	p := exp.NewProg(env)
+	p.File.URL = "testdata/"
+	_, err := p.RunStr("(import './rec1')", nil)
+	var ce *CycleError
+	if !errors.As(err, &ce) {
+		t.Fatalf("want cycle error got %v", err)
+	}
+	want := `module load recursion detected for file:testdata/rec1.xelf
+	testdata/rec1.xelf:2:9: import ./rec2
+	testdata/rec2.xelf:2:9: import ./rec1`
+	if got := ce.Error(); got != want {
+		t.Errorf("cycle error got:\n%s\nwant:\n%s", got, want)
+	}
+}
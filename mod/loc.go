@@ -47,6 +47,17 @@ func (l *Loc) Frag() string {
 	}
 	return ""
 }
+
+// ModFrag returns the fragment used to select modules from a loaded file.
+// Zip locations use the first fragment as archive path and a second fragment to select modules.
+func (l *Loc) ModFrag() string {
+	f := l.Frag()
+	if l.Proto() == "zip" {
+		_, f = splitZipFrag(f)
+	}
+	return f
+}
+
 func (l *Loc) Path() string {
 	if l == nil {
 		return ""
@@ -62,6 +73,25 @@ func (l *Loc) Path() string {
 	return p
 }
 
+// Version returns the module version of a version qualified path like 'company.com/prod@v2'.
+func (l *Loc) Version() string {
+	_, v, _ := splitVersion(l.Path())
+	return v
+}
+
 func (l Loc) String() string {
 	return l.URL
 }
+
+// splitVersion splits a version qualified path into the module path, version and the rest path.
+func splitVersion(p string) (mod, ver, rest string) {
+	idx := strings.LastIndexByte(p, '@')
+	if idx < 0 {
+		return p, "", ""
+	}
+	mod, ver = p[:idx], p[idx+1:]
+	if end := strings.IndexByte(ver, '/'); end >= 0 {
+		ver, rest = ver[:end], ver[end:]
+	}
+	return mod, ver, rest
+}
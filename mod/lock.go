@@ -0,0 +1,108 @@
+package mod
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"xelf.org/xelf/bfr"
+	"xelf.org/xelf/lit"
+)
+
+// Lock records the url, version and content hash of loaded module sources.
+//
+// A lock set on a loader environment records all newly loaded sources and verifies sources that
+// are already locked against their hash. A frozen lock also fails for sources that are not locked.
+// Lock files use the xelf format and can be written and read with WriteTo and ReadLock.
+// A lock can be shared by loader environments that load modules concurrently.
+type Lock struct {
+	Srcs   []LockSrc  `json:"srcs"`
+	Frozen bool       `json:"-"`
+	mu     sync.Mutex `json:"-"`
+}
+
+// LockSrc is a locked module source.
+type LockSrc struct {
+	URL     string `json:"url"`
+	Version string `json:"version,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+}
+
+// ReadLock reads and returns a lock file from r or an error.
+func ReadLock(r io.Reader, name string) (*Lock, error) {
+	l := &Lock{}
+	mut, err := lit.Proxy(lit.GlobalRegs(), l)
+	if err != nil {
+		return nil, err
+	}
+	err = lit.ReadInto(r, name, mut)
+	if err != nil {
+		return nil, fmt.Errorf("read lock %s: %v", name, err)
+	}
+	return l, nil
+}
+
+// WriteTo writes the lock sorted by url in xelf format to w.
+func (l *Lock) WriteTo(w io.Writer) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sort.Slice(l.Srcs, func(i, j int) bool { return l.Srcs[i].URL < l.Srcs[j].URL })
+	mut, err := lit.Proxy(lit.GlobalRegs(), l)
+	if err != nil {
+		return 0, err
+	}
+	var b strings.Builder
+	err = mut.Print(&bfr.P{Writer: &b, Tab: "\t"})
+	if err != nil {
+		return 0, err
+	}
+	b.WriteByte('\n')
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Find returns a copy of the locked source for url and whether it was found.
+func (l *Lock) Find(url string) (LockSrc, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if s := l.find(url); s != nil {
+		return *s, true
+	}
+	return LockSrc{}, false
+}
+
+func (l *Lock) find(url string) *LockSrc {
+	for i := range l.Srcs {
+		if l.Srcs[i].URL == url {
+			return &l.Srcs[i]
+		}
+	}
+	return nil
+}
+
+// Check verifies src against the locked source with the same url or records it if not frozen.
+// It returns an error if the hash or a non-empty version does not match or a frozen lock has no
+// source for the url.
+func (l *Lock) Check(src *Src, version string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ls := l.find(src.URL)
+	if ls == nil {
+		if l.Frozen {
+			return fmt.Errorf("module source %s is not locked", src.URL)
+		}
+		l.Srcs = append(l.Srcs, LockSrc{URL: src.URL, Version: version, Hash: src.Hash})
+		return nil
+	}
+	if ls.Hash != src.Hash {
+		return fmt.Errorf("module source %s does not match locked hash %s got %s",
+			src.URL, ls.Hash, src.Hash)
+	}
+	if ls.Version != "" && version != "" && ls.Version != version {
+		return fmt.Errorf("module source %s does not match locked version %s got %s",
+			src.URL, ls.Version, version)
+	}
+	return nil
+}
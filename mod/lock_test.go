@@ -0,0 +1,81 @@
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, raw string) {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(raw), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("liba.xelf", `(module liba name:"liba")`)
+	write("libb.xelf", `(import './liba') (module libb name:("libb using "+ liba.name))`)
+	run := func(l *Lock) error {
+		env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods(dir))
+		env.Lock = l
+		_, err := exp.NewProg(env).RunStr("(import 'libb')", nil)
+		return err
+	}
+	l := &Lock{}
+	if err := run(l); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var b strings.Builder
+	if _, err := l.WriteTo(&b); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	if got := b.String(); !strings.Contains(got, "url:'file:"+dir+"/liba.xelf'") ||
+		!strings.Contains(got, "url:'file:"+dir+"/libb.xelf'") ||
+		strings.Count(got, "hash:'sha256:") != 2 {
+		t.Errorf("unexpected lock file:\n%s", got)
+	}
+	l, err := ReadLock(strings.NewReader(b.String()), "xelf.lock")
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+	if len(l.Srcs) != 2 || l.Srcs[0].Hash == "" {
+		t.Fatalf("unexpected lock %+v", l)
+	}
+	ls, ok := l.Find(l.Srcs[0].URL)
+	if !ok || ls.Hash != l.Srcs[0].Hash {
+		t.Errorf("want locked source %+v got %+v", l.Srcs[0], ls)
+	}
+	ls.Hash = "changed"
+	if l.Srcs[0].Hash == "changed" {
+		t.Errorf("want find to return a copy")
+	}
+	if _, ok := l.Find("file:missing.xelf"); ok {
+		t.Errorf("want missing source not found")
+	}
+	l.Frozen = true
+	if err := run(l); err != nil {
+		t.Errorf("run locked: %v", err)
+	}
+	write("liba.xelf", `(module liba name:"changed")`)
+	err = run(l)
+	if err == nil || !strings.Contains(err.Error(), "does not match locked hash") {
+		t.Errorf("want hash mismatch error got %v", err)
+	}
+	l.Srcs[1].Version = "v1"
+	if err := l.Check(&Src{Loc: Loc{URL: l.Srcs[1].URL}, Hash: l.Srcs[1].Hash}, "v2"); err == nil ||
+		!strings.Contains(err.Error(), "does not match locked version") {
+		t.Errorf("want version mismatch error got %v", err)
+	}
+	write("libc.xelf", `(module libc)`)
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods(dir))
+	env.Lock = l
+	_, err = exp.NewProg(env).RunStr("(import 'libc')", nil)
+	if err == nil || !strings.Contains(err.Error(), "is not locked") {
+		t.Errorf("want not locked error got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package mod
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
 	"sync"
 
 	"xelf.org/xelf/ast"
@@ -10,6 +13,10 @@ import (
 
 var ErrFileNotFound = errors.New("mod file not found")
 
+// ErrRelPath is returned by loaders for relative module paths that they cannot resolve from the
+// base location. Like ErrFileNotFound it lets other loaders try the location.
+var ErrRelPath = errors.New("relative mod path not allowed here")
+
 // Registry provides a central global module registry for convenience.
 var Registry = new(SysMods)
 
@@ -24,10 +31,22 @@ type (
 type Src struct {
 	Rel string
 	Loc
+	// Hash is the content hash of file sources in the form 'sha256:hex'.
+	Hash  string
 	Raw   []ast.Ast
 	Setup func(*exp.Prog, *Src) (*File, error)
 }
 
+// readSrc reads all asts from r and returns them with the content hash or an error.
+func readSrc(r io.Reader, name string) ([]ast.Ast, string, error) {
+	h := sha256.New()
+	as, err := ast.ReadAll(io.TeeReader(r, h), name)
+	if err != nil {
+		return nil, "", err
+	}
+	return as, "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // Loader caches and loads module sources.
 type Loader interface {
 	LoadSrc(path, base *Loc) (*Src, error)
@@ -19,6 +19,7 @@ func TestModSpecs(t *testing.T) {
 	}{
 		{"no result", `(module foo)`, "null"},
 		{"decl type", `(import './foo') foo.Info`, "<obj@foo.Info>"},
+		{"decl tagged type", `(module bar Cat:<obj name:str>) bar.Cat`, "<obj@bar.Cat>"},
 		{"decl spec", `(import './foo') foo.rem`, "<form@foo.rem int int int>"},
 		{"decl type alias", `(import f:'./foo') f.Info`, "<obj@f.Info>"},
 		{"decl spec alias", `(import f:'./foo') f.rem`, "<form@f.rem int int int>"},
@@ -39,6 +40,22 @@ func TestModSpecs(t *testing.T) {
 	}
 }
 
+func TestModDoc(t *testing.T) {
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods())
+	p := exp.NewProg(env)
+	_, err := p.RunStr(`(module bar 'Bar is a test module.' Cat:<obj name:str>)`, nil)
+	if err != nil {
+		t.Fatalf("run module: %v", err)
+	}
+	_, d, err := lib.Docs.Lookup(p, "bar")
+	if err != nil {
+		t.Fatalf("lookup bar: %v", err)
+	}
+	if d == nil || d.Summary != "Bar is a test module." {
+		t.Errorf("want module doc got %v", d)
+	}
+}
+
 func TestSysMods(t *testing.T) {
 	setup := func(prog *exp.Prog, s *Src) (*File, error) {
 		f := &exp.File{URL: s.URL}
@@ -118,3 +135,49 @@ func TestFailMods(t *testing.T) {
 		}
 	}
 }
+
+func TestModUse(t *testing.T) {
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods())
+	tests := []struct {
+		raw  string
+		want string
+		err  string
+	}{
+		{raw: `(import './shop' use:['Prod']) Prod`, want: "<obj@shop.Prod>"},
+		{raw: `(import './shop' use:['Prod']) shop.Item`, want: "<obj@shop.Item>"},
+		{raw: `(import s:'./shop' use:['Prod']) Prod`, want: "<obj@s.Prod>"},
+		{raw: `(import './shop' use:'*') (hello 'you')`, want: "hello you"},
+		{raw: `(import './shop' use:[Prod hello]) (hello 'sym')`, want: "hello sym"},
+		{raw: `(import './shop' use:[Prod hello]) Prod`, want: "<obj@shop.Prod>"},
+		{raw: `(import './reshop' use:['hello']) (hello 'me')`, want: "hello me"},
+		{raw: `(import './reshop') shop.Prod`, want: "<obj@shop.Prod>"},
+		{raw: `(import './shop') shop._greeting`, err: "unresolved"},
+		{raw: `(import './reshop') shop.Item`, err: "unresolved"},
+		{raw: `(import './reshop' use:['Item'])`, err: "no declaration Item"},
+		{raw: `(import './shop' use:['Prod'] s:'./shop' use:'*')`, err: "already used"},
+		{raw: `(import use:['Prod'])`, err: "without module path"},
+	}
+	for _, test := range tests {
+		x, err := exp.Parse(test.raw)
+		if err != nil {
+			t.Errorf("%s parse failed: %v", test.raw, err)
+			continue
+		}
+		p := exp.NewProg(env)
+		p.File.URL = "testdata/"
+		res, err := p.Run(x, nil)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s want error %q got %v", test.raw, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s got error: %v", test.raw, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("%s got %s want %s", test.raw, got, test.want)
+		}
+	}
+}
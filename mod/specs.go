@@ -1,9 +1,11 @@
 package mod
 
 import (
+	"errors"
 	"fmt"
 
 	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
 	"xelf.org/xelf/exp"
 	"xelf.org/xelf/knd"
 	"xelf.org/xelf/lit"
@@ -24,8 +26,18 @@ func (s *moduleSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 	c.Env = me
 	// eval elements to build the result type and value
 	tags := c.Args[1].(*exp.Tupl)
+	els := tags.Els
+	// a leading plain string is the module documentation
+	if len(els) > 0 {
+		if l, ok := els[0].(*exp.Lit); ok {
+			if doc, ok := lit.Unwrap(l.Val).(lit.Char); ok {
+				me.Mod.Doc = string(doc)
+				els = els[1:]
+			}
+		}
+	}
 	// create module type
-	for _, el := range tags.Els {
+	for _, el := range els {
 		tag, _ := el.(*exp.Tag)
 		if tag != nil {
 			el = tag.Exp
@@ -38,6 +50,10 @@ func (s *moduleSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 		if err != nil {
 			return nil, err
 		}
+		if tag == nil && isTest(el) {
+			// test groups are registered with the loader and not declared
+			continue
+		}
 		val, err := p.Eval(c.Env, el)
 		if err != nil {
 			return nil, err
@@ -45,8 +61,13 @@ func (s *moduleSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 		vt := val.Type()
 		var decl string
 		if tag != nil {
-			tag.Exp = exp.LitVal(val)
 			decl = tag.Tag
+			if t, ok := val.(typ.Type); ok && t.Ref == "" && t.Kind&namedKinds != 0 {
+				// tagged obj, enum and bits types are named by their declaration
+				t.Ref = me.Mod.Name + "." + decl
+				val = t
+			}
+			tag.Exp = exp.LitVal(val)
 		} else if vt.Kind&knd.Typ != 0 {
 			t, err := typ.ToType(val)
 			if err != nil || t.Ref == "" {
@@ -74,6 +95,20 @@ func (s *moduleSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 	return c, me.Publish()
 }
 
+// isTest returns whether e is a resolved test form call.
+func isTest(e exp.Exp) bool {
+	c, ok := e.(*exp.Call)
+	if !ok {
+		return false
+	}
+	if r := exp.UnwrapSpec(c.Spec); r != nil {
+		return r.Spec == Test
+	}
+	return c.Spec == Test
+}
+
+const namedKinds = knd.Obj | knd.Enum | knd.Bits
+
 func (s *moduleSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
 	return lit.Null{}, nil
 }
@@ -93,10 +128,16 @@ func (s *importSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 	// lookup the loader env
 	le := FindLoaderEnv(p.Root)
 	top, _ := c.Args[0].(*exp.Tupl)
-	for _, el := range top.Els {
+	els := top.Els
+	for i := 0; i < len(els); i++ {
 		// get alias and path from argument
+		el := els[i]
 		var ref ModRef
+		src := el.Source()
 		if t, ok := el.(*exp.Tag); ok {
+			if _, ok, _ := UseTag(t); ok {
+				return nil, fmt.Errorf("unexpected use argument without module path")
+			}
 			ref.Alias = t.Tag
 			el = t.Exp
 		}
@@ -105,13 +146,29 @@ func (s *importSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 		} else {
 			return nil, fmt.Errorf("unexpected use argument %T", el)
 		}
+		// a following use tag selects declarations used unqualified
+		if i+1 < len(els) {
+			use, ok, err := UseTag(els[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				ref.Use = use
+				i++
+			}
+		}
 		// load module using loader env
 		loc := ParseLoc(ref.Path)
 		f, err := le.LoadFile(p, loc)
 		if err != nil {
+			var ce *CycleError
+			if errors.As(err, &ce) {
+				ce.addStep(p.File.URL, ref.Path, src)
+				return nil, ce
+			}
 			return nil, err
 		}
-		refs := filterRefs(f.Refs, loc.Frag())
+		refs := filterRefs(f.Refs, loc.ModFrag())
 		if ref.Alias != "" {
 			if len(refs) > 1 {
 				refs = filterRefs(refs, ref.Alias)
@@ -120,16 +177,33 @@ func (s *importSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (_
 		if len(refs) == 0 {
 			return nil, fmt.Errorf("no modules found for %s", ref.Path)
 		}
+		for _, name := range ref.Use {
+			if name != "*" && !anyDecl(refs, name) {
+				return nil, fmt.Errorf("no declaration %s found for %s", name, ref.Path)
+			}
+		}
 		// register modules in parent loader or mod env local
 		for _, m := range refs {
 			if !m.Pub {
 				continue
 			}
 			m.Path = ref.Path
+			m.Version = loc.Version()
 			if ref.Alias != "" {
 				m.Alias = ref.Alias
 			}
 			m.Pub = s.export
+			m.Use = usedDecls(m.Mod, ref.Use)
+			if s.export && len(m.Use) > 0 && m.Use[0] != "*" {
+				// only re-export the used declarations
+				m.Mod = subMod(m.Mod, m.Use)
+			}
+			for _, name := range m.Use {
+				if o := findUse(p.File.Refs, m, name); o != nil {
+					return nil, fmt.Errorf("declaration %s of %s is already used from %s",
+						name, m.Key(), o.Key())
+				}
+			}
 			err = p.File.AddRefs(m)
 			if err != nil {
 				return nil, err
@@ -157,4 +231,96 @@ func filterRefs(refs []exp.ModRef, find string) (pub []exp.ModRef) {
 	return pub
 }
 
+// UseTag returns the declaration names of a use tag, whether el is a use tag or an error.
+// Use tags have either a list of names or the wildcard '*' as value.
+func UseTag(el exp.Exp) ([]string, bool, error) {
+	t, ok := el.(*exp.Tag)
+	if !ok || t.Tag != "use" {
+		return nil, false, nil
+	}
+	l, ok := t.Exp.(*exp.Lit)
+	if !ok {
+		return nil, false, nil
+	}
+	switch v := lit.Unwrap(l.Val).(type) {
+	case lit.Char:
+		if v != "*" {
+			// other strings are import paths with the alias use
+			return nil, false, nil
+		}
+		return []string{"*"}, true, nil
+	case lit.Idxr:
+		res := make([]string, 0, v.Len())
+		err := v.IterIdx(func(i int, v lit.Val) error {
+			name, err := lit.ToStr(v)
+			if err != nil || !cor.IsName(string(name)) {
+				return fmt.Errorf("invalid use declaration name %s", v)
+			}
+			res = append(res, string(name))
+			return nil
+		})
+		return res, err == nil, err
+	}
+	return nil, false, nil
+}
+
+// usedDecls returns the names in use that m declares or the wildcard.
+func usedDecls(m *Mod, use []string) (res []string) {
+	for _, name := range use {
+		if name == "*" {
+			return []string{"*"}
+		}
+		if m.HasDecl(name) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+func anyDecl(refs []exp.ModRef, name string) bool {
+	for _, m := range refs {
+		if m.HasDecl(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findUse returns another module reference in refs that already uses name of m or nil.
+func findUse(refs []exp.ModRef, m exp.ModRef, name string) *exp.ModRef {
+	for i, o := range refs {
+		if len(o.Use) == 0 {
+			continue
+		}
+		if name == "*" {
+			if pb, ok := m.Decl.Typ.Body.(*typ.ParamBody); ok {
+				for _, p := range pb.Params {
+					if o.Uses(p.Key) {
+						return &refs[i]
+					}
+				}
+			}
+		} else if o.Uses(name) {
+			return &refs[i]
+		}
+	}
+	return nil
+}
+
+// subMod returns a copy of m that only declares the names in use.
+func subMod(m *Mod, use []string) *Mod {
+	pb, _ := m.Decl.Typ.Body.(*typ.ParamBody)
+	sub := *m
+	sub.Decl = newDecl()
+	sub.Decl.Typ.Ref = m.Decl.Typ.Ref
+	spb := sub.Decl.Typ.Body.(*typ.ParamBody)
+	for _, name := range use {
+		if idx := pb.FindKeyIndex(cor.Keyed(name)); idx >= 0 {
+			spb.Params = append(spb.Params, pb.Params[idx])
+			sub.Decl.Vals = append(sub.Decl.Vals, m.Decl.Vals[idx])
+		}
+	}
+	return &sub
+}
+
 var impl = exp.MustSpecBase
@@ -0,0 +1,218 @@
+package mod
+
+import (
+	"errors"
+	"fmt"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Test is the form that groups named test cases in a module file. It can be used in or after a
+// module declaration. The tagged cases are only resolved and evaluated by the test runner.
+//
+//	(module helpers double:(fn (mul _ 2))
+//		(test double
+//			two:(expect_eq (double 2) 4)
+//			neg:(expect_eq (double -1) -2)))
+var Test = &testSpec{impl("<form@test name:sym cases:tupl|exp none>")}
+
+type testSpec struct{ exp.SpecBase }
+
+func (s *testSpec) Resl(p *exp.Prog, env exp.Env, c *exp.Call, _ typ.Type) (exp.Exp, error) {
+	if c.Env != nil {
+		return c, nil
+	}
+	g := &TestGroup{Name: c.Args[0].String(), File: p.File.URL, Src: c.Src, prog: p, env: env}
+	if me := FindModEnv(env); me != nil {
+		g.Mod = me.Mod.Name
+	}
+	for _, el := range c.Args[1].(*exp.Tupl).Els {
+		tag, ok := el.(*exp.Tag)
+		if !ok || tag.Exp == nil {
+			return nil, ast.ErrReslSpec(el.Source(), c.Sig.Ref,
+				fmt.Errorf("expect tagged test case got %s", el))
+		}
+		if g.Case(tag.Tag) != nil {
+			return nil, ast.ErrReslSpec(tag.Src, c.Sig.Ref,
+				fmt.Errorf("test case name %q is not unique", tag.Tag))
+		}
+		g.Cases = append(g.Cases, TestCase{Name: tag.Tag, Src: tag.Src, Exp: tag.Exp})
+	}
+	if le := FindLoaderEnv(p.Root); le != nil {
+		le.addTest(g)
+	}
+	c.Env = env
+	return c, nil
+}
+
+func (s *testSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	return lit.Null{}, nil
+}
+
+// TestGroup is a named group of test cases declared by a test form.
+type TestGroup struct {
+	Name string
+	// File is the url of the declaring file.
+	File string
+	// Mod is the name of the enclosing module or empty.
+	Mod   string
+	Src   ast.Src
+	Cases []TestCase
+	prog  *exp.Prog
+	env   exp.Env
+}
+
+// TestCase is a named and unresolved test case expression.
+type TestCase struct {
+	Name string
+	Src  ast.Src
+	Exp  exp.Exp
+}
+
+// Case returns the test case with name or nil.
+func (g *TestGroup) Case(name string) *TestCase {
+	for i, c := range g.Cases {
+		if c.Name == name {
+			return &g.Cases[i]
+		}
+	}
+	return nil
+}
+
+// Run resolves and evaluates a copy of test case c in a child program of the declaring program.
+// The assert and expect specs are available to the case. The case fails if it returns an error or
+// the bool value false.
+func (g *TestGroup) Run(c *TestCase) *TestResult {
+	res := &TestResult{Group: g, Case: c, Src: c.Src}
+	p := childProg(g.prog)
+	env := &testEnv{Par: g.env}
+	x, err := p.Resl(env, c.Exp.Clone(), typ.Void)
+	var v lit.Val
+	if err == nil {
+		v, err = p.Eval(env, x)
+	}
+	if err == nil {
+		if b, ok := v.(lit.Bool); ok && !bool(b) {
+			err = ast.ErrFailed(c.Src, "test "+c.Name, fmt.Errorf("case result is false"))
+		}
+	}
+	if err != nil {
+		res.Err = err
+		// report the position of the innermost error
+		for e := error(err); e != nil; {
+			var ae *ast.Error
+			if !errors.As(e, &ae) {
+				break
+			}
+			if ae.Src.Line > 0 {
+				res.Src = ae.Src
+			}
+			e = ae.Err
+		}
+		var f *lib.Failure
+		if errors.As(err, &f) {
+			res.Diff = f.Diff
+		}
+	}
+	return res
+}
+
+// TestResult is the outcome of a test case run.
+type TestResult struct {
+	Group *TestGroup
+	Case  *TestCase
+	// Src is the position of the failed assertion or otherwise of the test case.
+	Src ast.Src
+	// Err is the error of failed test cases.
+	Err error
+	// Diff is the delta from the got to the wanted value of failed expectations.
+	Diff lit.Delta
+}
+
+// Pass returns whether the test case passed.
+func (r *TestResult) Pass() bool { return r.Err == nil }
+
+// Name returns the group and case name separated by a slash.
+func (r *TestResult) Name() string { return r.Group.Name + "/" + r.Case.Name }
+
+func (r *TestResult) String() string {
+	if r.Err == nil {
+		return fmt.Sprintf("%s: pass %s", r.Src, r.Name())
+	}
+	return fmt.Sprintf("%s: fail %s\n\t%v", r.Src, r.Name(), r.Err)
+}
+
+// RunTests runs all cases of the test groups and returns the results in order.
+func RunTests(gs []*TestGroup) []*TestResult {
+	var res []*TestResult
+	for _, g := range gs {
+		for i := range g.Cases {
+			res = append(res, g.Run(&g.Cases[i]))
+		}
+	}
+	return res
+}
+
+// Tests returns all test groups declared in files loaded with this loader env in load order.
+func (le *LoaderEnv) Tests() []*TestGroup {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	return append([]*TestGroup(nil), le.tests...)
+}
+
+// LoadTests loads the files at the urls and returns the test groups declared in them or an error.
+func (le *LoaderEnv) LoadTests(p *exp.Prog, urls ...string) ([]*TestGroup, error) {
+	var res []*TestGroup
+	for _, url := range urls {
+		f, err := le.LoadFile(p, ParseLoc(url))
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range le.Tests() {
+			if g.File == f.URL {
+				res = append(res, g)
+			}
+		}
+	}
+	return res, nil
+}
+
+// addTest adds or replaces the test group with the same file and name.
+func (le *LoaderEnv) addTest(g *TestGroup) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	for i, o := range le.tests {
+		if o.File == g.File && o.Name == g.Name {
+			le.tests[i] = g
+			return
+		}
+	}
+	le.tests = append(le.tests, g)
+}
+
+// childProg returns a copy of program p with a copy of its type system, so that test cases do not
+// change the program state or each other.
+func childProg(p *exp.Prog) *exp.Prog {
+	c := *p
+	c.Sys = &typ.Sys{MaxID: p.Sys.MaxID, Map: make(map[int32]typ.Type, len(p.Sys.Map))}
+	for id, t := range p.Sys.Map {
+		c.Sys.Map[id] = t
+	}
+	return &c
+}
+
+// testEnv provides the assert and expect specs to test cases.
+type testEnv struct{ Par exp.Env }
+
+func (e *testEnv) Parent() exp.Env { return e.Par }
+func (e *testEnv) Lookup(s *exp.Sym, p cor.Path, eval bool) (lit.Val, error) {
+	if v, err := lib.Asserts.Lookup(s, p, eval); err == nil {
+		return v, nil
+	}
+	return e.Par.Lookup(s, p, eval)
+}
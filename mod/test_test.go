@@ -0,0 +1,70 @@
+package mod
+
+import (
+	"fmt"
+	"testing"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+)
+
+func TestLoadTests(t *testing.T) {
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods())
+	p := exp.NewProg(env)
+	p.File.URL = "testdata/"
+	gs, err := env.LoadTests(p, "./tested")
+	if err != nil {
+		t.Fatalf("load tests: %v", err)
+	}
+	if len(gs) != 2 || gs[0].Mod != "tested" || gs[1].Mod != "" {
+		t.Fatalf("want two test groups got %v", gs)
+	}
+	var names []string
+	for _, r := range RunTests(gs) {
+		if !r.Pass() {
+			t.Errorf("%s", r)
+		}
+		names = append(names, r.Name())
+	}
+	want := "[double/two double/neg double/half after/sel after/err]"
+	if got := fmt.Sprint(names); got != want {
+		t.Errorf("want results %s got %s", want, got)
+	}
+}
+
+func TestRunTestsFail(t *testing.T) {
+	env := NewLoaderEnv(exp.Builtins(lib.Std), FileMods())
+	p := exp.NewProg(env)
+	_, err := p.RunStr(`(module calc sq:(fn (mul _ _))
+		(test sq
+			ok:(expect_eq (sq 2) 4)
+			obj:(expect_eq (mut {a:1 b:2} a:(sq 3)) {a:10 b:2})
+			bool:(eq (sq 1) 2)
+			err:(sq 'a')))`, nil)
+	if err != nil {
+		t.Fatalf("run module: %v", err)
+	}
+	res := RunTests(env.Tests())
+	if len(res) != 4 {
+		t.Fatalf("want four results got %d", len(res))
+	}
+	if !res[0].Pass() {
+		t.Errorf("want pass got %s", res[0])
+	}
+	fail := res[1]
+	if fail.Pass() || fail.Diff.String() != "{a:10}" {
+		t.Errorf("want fail with diff got %s diff %s", fail, fail.Diff)
+	}
+	if fail.Src.Line != 4 || fail.Src == fail.Case.Src {
+		t.Errorf("want failed assertion position got %s", fail.Src)
+	}
+	for _, r := range res[2:] {
+		if r.Pass() {
+			t.Errorf("want %s to fail", r.Name())
+		}
+	}
+	// assertions are only available to test cases
+	if _, err = exp.NewProg(env).RunStr(`(assert true)`, nil); err == nil {
+		t.Errorf("want assert to be unresolved outside of test cases")
+	}
+}
@@ -0,0 +1,76 @@
+package mod
+
+import (
+	"strings"
+	"sync"
+)
+
+// VersionMods is a module loader for version qualified module paths like 'company.com/prod@v2'.
+//
+// Map maps module paths with version to a location, usually a 'file:' directory or a 'zip:'
+// archive location with inner path. File directories are loaded by a file loader rooted at the
+// mapped directory. Other locations get the rest of the module path and the location fragment
+// appended and are then loaded with the first loader that finds it.
+// Every version resolves to a distinct source url, so that multiple versions of a module can be
+// imported in one program under different aliases.
+type VersionMods struct {
+	Map     map[string]string
+	Loaders []Loader
+
+	mu   sync.Mutex
+	dirs map[string]*FSMods
+}
+
+// NewVersionMods returns a new version module loader with the version map m and loaders ls.
+func NewVersionMods(m map[string]string, ls ...Loader) *VersionMods {
+	return &VersionMods{Map: m, Loaders: ls}
+}
+
+func (vm *VersionMods) LoadSrc(raw, base *Loc) (*Src, error) {
+	if raw.Proto() != "" {
+		return nil, ErrFileNotFound
+	}
+	mod, ver, rest := splitVersion(raw.Path())
+	if ver == "" {
+		return nil, ErrFileNotFound
+	}
+	target, ok := vm.Map[mod+"@"+ver]
+	if !ok {
+		// other loaders may find version directories
+		return nil, ErrFileNotFound
+	}
+	frag := raw.Frag()
+	if frag != "" {
+		frag = "#" + frag
+	}
+	if dir := ParseLoc(target); dir.Proto() == "file" {
+		rel := strings.TrimPrefix(rest, "/")
+		if rel == "" {
+			rel = "."
+		}
+		return vm.dirMods(dir.Path()).LoadSrc(ParseLoc(rel+frag), nil)
+	}
+	loc := ParseLoc(target + rest + frag)
+	for _, l := range vm.Loaders {
+		src, err := l.LoadSrc(loc, base)
+		if err != ErrFileNotFound && err != ErrRelPath {
+			return src, err
+		}
+	}
+	return nil, ErrFileNotFound
+}
+
+// dirMods returns a file loader rooted at the version directory dir.
+func (vm *VersionMods) dirMods(dir string) *FSMods {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	fm := vm.dirs[dir]
+	if fm == nil {
+		if vm.dirs == nil {
+			vm.dirs = make(map[string]*FSMods)
+		}
+		fm = FileMods(dir)
+		vm.dirs[dir] = fm
+	}
+	return fm
+}
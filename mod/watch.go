@@ -0,0 +1,159 @@
+package mod
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+
+	"xelf.org/xelf/exp"
+)
+
+// Change describes changed module sources and all files that depend on them.
+type Change struct {
+	// Changed holds the sorted urls of changed or removed sources.
+	Changed []string
+	// Affected holds the sorted urls of changed sources and all their known dependents.
+	Affected []string
+}
+
+// Affects returns whether program p loaded any of the changed sources.
+func (c *Change) Affects(p *exp.Prog) bool {
+	for _, url := range c.Changed {
+		if _, ok := p.Files[url]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Watcher polls the cached sources of a file module loader for changes. Changed sources are
+// removed from the loader cache and subscribers are notified with the changed sources and their
+// dependents. Dependencies are collected from the module graph of tracked programs.
+type Watcher struct {
+	Mods *FSMods
+
+	mu    sync.Mutex
+	stats map[string]srcStat
+	deps  map[string]map[string]bool
+	subs  []func(*Change)
+}
+
+type srcStat struct {
+	mod  time.Time
+	size int64
+}
+
+// NewWatcher returns a new watcher for the file module loader fm.
+func NewWatcher(fm *FSMods) *Watcher {
+	return &Watcher{Mods: fm, stats: make(map[string]srcStat), deps: make(map[string]map[string]bool)}
+}
+
+// Subscribe registers f to be called with every change detected by the watcher.
+func (w *Watcher) Subscribe(f func(*Change)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, f)
+}
+
+// Track adds the module dependencies of the resolved program p to the watcher.
+func (w *Watcher) Track(p *exp.Prog) {
+	g := NewGraph(p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, f := range g.Files {
+		if f.URL == "" {
+			// files without url, like the program itself, cannot be reported as affected
+			continue
+		}
+		for _, dep := range g.Deps(f.URL) {
+			ds := w.deps[dep]
+			if ds == nil {
+				ds = make(map[string]bool)
+				w.deps[dep] = ds
+			}
+			ds[f.URL] = true
+		}
+	}
+}
+
+// Check polls all cached sources once and returns the change or nil if nothing changed.
+// Changed sources are forgotten by the loader before subscribers are notified.
+func (w *Watcher) Check() *Change {
+	srcs := w.Mods.cached()
+	w.mu.Lock()
+	var changed []string
+	for _, s := range srcs {
+		fi, err := fs.Stat(s.FS, s.Rel)
+		if err != nil {
+			delete(w.stats, s.URL)
+			changed = append(changed, s.URL)
+			continue
+		}
+		st := srcStat{fi.ModTime(), fi.Size()}
+		old, ok := w.stats[s.URL]
+		w.stats[s.URL] = st
+		if ok && old == st {
+			continue
+		}
+		// only report actual content changes
+		if raw, err := fs.ReadFile(s.FS, s.Rel); err != nil || hashRaw(raw) != s.Hash {
+			changed = append(changed, s.URL)
+		}
+	}
+	if len(changed) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	sort.Strings(changed)
+	c := &Change{Changed: changed, Affected: w.affected(changed)}
+	subs := w.subs
+	w.mu.Unlock()
+	w.Mods.Forget(changed...)
+	for _, f := range subs {
+		f(c)
+	}
+	return c
+}
+
+// Run calls Check every interval until the context is done and returns the context error.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			w.Check()
+		}
+	}
+}
+
+func (w *Watcher) affected(changed []string) []string {
+	seen := make(map[string]bool, len(changed))
+	res := make([]string, 0, len(changed))
+	todo := append([]string(nil), changed...)
+	for len(todo) > 0 {
+		url := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+		res = append(res, url)
+		for dep := range w.deps[url] {
+			todo = append(todo, dep)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func hashRaw(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
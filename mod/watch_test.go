@@ -0,0 +1,74 @@
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, raw string, mod time.Time) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write("liba.xelf", `(module liba name:"liba")`, now)
+	write("libb.xelf", `(import './liba') (module libb name:("libb using "+ liba.name))`, now)
+	write("libc.xelf", `(module libc)`, now)
+	fm := FileMods(dir)
+	env := NewLoaderEnv(exp.Builtins(lib.Std), fm)
+	run := func() (*exp.Prog, string) {
+		p := exp.NewProg(env)
+		res, err := p.RunStr("(import 'libb' 'libc') libb.name", nil)
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		return p, res.String()
+	}
+	w := NewWatcher(fm)
+	var notes []*Change
+	w.Subscribe(func(c *Change) { notes = append(notes, c) })
+	p, got := run()
+	if got != "libb using liba" {
+		t.Errorf("unexpected result %s", got)
+	}
+	w.Track(p)
+	if c := w.Check(); c != nil {
+		t.Errorf("unexpected change %v", c)
+	}
+	// touching a file without changing the content is no change
+	write("libc.xelf", `(module libc)`, now.Add(time.Second))
+	if c := w.Check(); c != nil {
+		t.Errorf("unexpected change %v", c)
+	}
+	write("liba.xelf", `(module liba name:"new liba")`, now.Add(2*time.Second))
+	c := w.Check()
+	url := func(name string) string { return "file:" + filepath.ToSlash(filepath.Join(dir, name)) }
+	want := &Change{
+		Changed:  []string{url("liba.xelf")},
+		Affected: []string{url("liba.xelf"), url("libb.xelf")},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("want change %v got %v", want, c)
+	}
+	if len(notes) != 1 || notes[0] != c {
+		t.Errorf("want one notification got %v", notes)
+	}
+	if !c.Affects(p) {
+		t.Errorf("change should affect program")
+	}
+	if _, got = run(); got != "libb using new liba" {
+		t.Errorf("want reloaded result got %s", got)
+	}
+}
@@ -0,0 +1,129 @@
+package mod
+
+import (
+	"archive/zip"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ZipMods is a module loader for zip locations in the form 'zip:archive.zip#inner/path#mod'.
+// The archive is an os path and the inner path is resolved like a file module path inside the
+// archive. Relative module paths are resolved inside the archive of the base location.
+type ZipMods struct {
+	Ext   []string
+	Index []string
+
+	Log  func(arc, path string)
+	mu   sync.Mutex
+	arcs map[string]*zipArc
+}
+
+type zipArc struct {
+	*zip.ReadCloser
+	cache map[string]*Src
+}
+
+// NewZipMods returns a new zip module loader.
+func NewZipMods() *ZipMods {
+	return &ZipMods{Ext: []string{".xelf"}, Index: []string{"mod.xelf"}}
+}
+
+func (zm *ZipMods) LoadSrc(raw, base *Loc) (*Src, error) {
+	var arc, inner string
+	switch raw.Proto() {
+	case "zip":
+		arc = raw.Path()
+		inner, _ = splitZipFrag(raw.Frag())
+	case "":
+		p := raw.Path()
+		if !strings.HasPrefix(p, "./") || base.Proto() != "zip" {
+			return nil, ErrFileNotFound
+		}
+		arc = base.Path()
+		bp, _ := splitZipFrag(base.Frag())
+		inner = path.Join(path.Dir(bp), p[2:])
+	default:
+		return nil, ErrFileNotFound
+	}
+	if arc == "" {
+		return nil, ErrFileNotFound
+	}
+	inner = path.Clean(strings.TrimPrefix(inner, "/"))
+	zm.mu.Lock()
+	defer zm.mu.Unlock()
+	a, err := zm.open(arc)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := a.cache[inner]; ok {
+		if s == nil {
+			return nil, ErrFileNotFound
+		}
+		return s, nil
+	}
+	found := findFile(a, inner, zm.Ext, zm.Index)
+	if found == "" {
+		a.cache[inner] = nil
+		return nil, ErrFileNotFound
+	}
+	if s := a.cache[found]; s != nil {
+		a.cache[inner] = s
+		return s, nil
+	}
+	if zm.Log != nil {
+		zm.Log(arc, found)
+	}
+	f, err := a.Open(found)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	url := "zip:" + arc + "#" + found
+	as, hash, err := readSrc(f, url)
+	if err != nil {
+		return nil, err
+	}
+	src := &Src{Rel: found, Loc: Loc{URL: url}, Hash: hash, Raw: as}
+	a.cache[inner] = src
+	a.cache[found] = src
+	return src, nil
+}
+
+// Close closes all archives opened by this loader and clears the source cache.
+func (zm *ZipMods) Close() error {
+	zm.mu.Lock()
+	defer zm.mu.Unlock()
+	var res error
+	for _, a := range zm.arcs {
+		if err := a.Close(); err != nil && res == nil {
+			res = err
+		}
+	}
+	zm.arcs = nil
+	return res
+}
+
+func (zm *ZipMods) open(arc string) (*zipArc, error) {
+	if a := zm.arcs[arc]; a != nil {
+		return a, nil
+	}
+	r, err := zip.OpenReader(arc)
+	if err != nil {
+		return nil, err
+	}
+	if zm.arcs == nil {
+		zm.arcs = make(map[string]*zipArc)
+	}
+	a := &zipArc{ReadCloser: r, cache: make(map[string]*Src)}
+	zm.arcs[arc] = a
+	return a, nil
+}
+
+// splitZipFrag splits the fragment of a zip location into the inner path and module fragment.
+func splitZipFrag(frag string) (inner, mod string) {
+	if idx := strings.IndexByte(frag, '#'); idx >= 0 {
+		return frag[:idx], frag[idx+1:]
+	}
+	return frag, ""
+}
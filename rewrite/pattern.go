@@ -0,0 +1,156 @@
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/knd"
+)
+
+// Caps maps capture names to matched nodes.
+type Caps map[string]*ast.Node
+
+// Pattern matches nodes by their syntax. Patterns are xelf sources with these rules:
+//
+//	_       matches any node
+//	?name   matches any node and captures it as name
+//	leaf    matches leafs of the same kind and value, strings are compared unquoted
+//	key:p   matches tags with the same key and a value matching p
+//	key;    matches tags with the same key and any or no value
+//	tree    matches trees of the same kind, the untagged elements of the pattern must match
+//	        the first untagged elements of the tree in order and every tag of the pattern must
+//	        match a tag of the tree
+//
+// For example '(prod.Prod name:_)' matches all calls to prod.Prod with a name tag.
+type Pattern struct {
+	Ast ast.Ast
+}
+
+// Compile parses the pattern source pat and returns the pattern or an error.
+func Compile(pat string) (*Pattern, error) {
+	// we read the pattern as call to allow top-level tags
+	a, err := ast.Read(strings.NewReader("("+pat+")"), "pattern")
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Seq) != 1 {
+		return nil, fmt.Errorf("pattern must have exactly one node got %d", len(a.Seq))
+	}
+	return &Pattern{Ast: a.Seq[0]}, nil
+}
+
+// MustCompile parses the pattern source pat and returns the pattern or panics.
+func MustCompile(pat string) *Pattern {
+	p, err := Compile(pat)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match returns the captures and whether n matches the pattern.
+func (p *Pattern) Match(n *ast.Node) (Caps, bool) {
+	caps := make(Caps)
+	if !match(p.Ast, n, caps) {
+		return nil, false
+	}
+	return caps, true
+}
+
+func isCap(sym string) bool { return len(sym) > 1 && sym[0] == '?' }
+
+func match(p ast.Ast, n *ast.Node, caps Caps) bool {
+	if p.Kind == knd.Sym {
+		if p.Raw == "_" {
+			return true
+		}
+		if isCap(p.Raw) {
+			name := p.Raw[1:]
+			if prev := caps[name]; prev != nil {
+				return prev.Ast().String() == n.Ast().String()
+			}
+			caps[name] = n
+			return true
+		}
+	}
+	if p.Kind != n.Kind {
+		return false
+	}
+	switch p.Kind {
+	case knd.Tag:
+		pk, _, _ := ast.UnquotePair(p)
+		if nk, _ := tagKey(n); pk != nk {
+			return false
+		}
+		if p.Rune == ';' || len(p.Seq) < 2 {
+			return true
+		}
+		return len(n.Seq) > 1 && match(p.Seq[1], n.Seq[1], caps)
+	case knd.Idxr, knd.Keyr, knd.Typ, knd.Call:
+		return matchSeq(p.Seq, n.Seq, caps)
+	case knd.Char:
+		pv, err := cor.Unquote(p.Raw)
+		if err != nil {
+			return false
+		}
+		nv, err := cor.Unquote(n.Raw)
+		return err == nil && pv == nv
+	}
+	return p.Raw == n.Raw && p.Rune == n.Rune
+}
+
+func matchSeq(ps []ast.Ast, ns []*ast.Node, caps Caps) bool {
+	var els []*ast.Node
+	for _, n := range ns {
+		if n.Kind != knd.Tag {
+			els = append(els, n)
+		}
+	}
+	i := 0
+	for _, p := range ps {
+		if p.Kind == knd.Tag {
+			if !matchTag(p, ns, caps) {
+				return false
+			}
+			continue
+		}
+		if i >= len(els) || !match(p, els[i], caps) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func matchTag(p ast.Ast, ns []*ast.Node, caps Caps) bool {
+	for _, n := range ns {
+		if n.Kind != knd.Tag {
+			continue
+		}
+		// match on a copy, so that failed attempts do not leave captures
+		try := make(Caps, len(caps))
+		for k, v := range caps {
+			try[k] = v
+		}
+		if match(p, n, try) {
+			for k, v := range try {
+				caps[k] = v
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func tagKey(n *ast.Node) (string, error) {
+	if len(n.Seq) == 0 {
+		return "", nil
+	}
+	k := n.Seq[0]
+	if k.Kind == knd.Char {
+		return cor.Unquote(k.Raw)
+	}
+	return k.Raw, nil
+}
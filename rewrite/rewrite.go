@@ -0,0 +1,353 @@
+// Package rewrite provides queries and in-place edits of xelf source files for codemods.
+//
+// Files are read as lossless concrete syntax trees, so that only edited nodes change in the output.
+// Nodes are found by pattern or path and can be replaced, removed or have nodes inserted next to
+// them. New nodes are given as source templates, that can refer to pattern captures, or as asts
+// that are printed using the file formatter.
+package rewrite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/knd"
+)
+
+// File is a concrete syntax tree of a xelf source with a formatter for new ast nodes.
+type File struct {
+	*ast.File
+	// Fmt formats asts used as new nodes and defaults to the standard simple format.
+	Fmt ast.Formatter
+}
+
+// Read reads and returns the named source file from r or an error.
+func Read(r io.Reader, name string) (*File, error) {
+	f, err := ast.ReadFile(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: f}, nil
+}
+
+// Match is a node found by a query with its ancestors and pattern captures.
+// Edits can invalidate other matches that are nested in the edited node.
+type Match struct {
+	Node *ast.Node
+	// Path holds the ancestors of node starting with the top-level node.
+	Path []*ast.Node
+	// Caps holds the nodes captured by name with '?name' pattern symbols.
+	Caps Caps
+
+	f *File
+}
+
+// Find returns matches for all nodes matching pattern p in depth-first order.
+// Tag keys are not matched on their own, but tags and tag values are.
+func (f *File) Find(p *Pattern) (res []*Match) {
+	var walk func(n *ast.Node, path []*ast.Node)
+	walk = func(n *ast.Node, path []*ast.Node) {
+		if caps, ok := p.Match(n); ok {
+			res = append(res, &Match{Node: n, Path: path, Caps: caps, f: f})
+		}
+		seq := n.Seq
+		if n.Kind == knd.Tag {
+			if len(seq) < 2 {
+				return
+			}
+			seq = seq[1:]
+		}
+		sub := append(path[:len(path):len(path)], n)
+		for _, el := range seq {
+			walk(el, sub)
+		}
+	}
+	for _, n := range f.Nodes {
+		walk(n, nil)
+	}
+	return res
+}
+
+// Select returns matches for all nodes at path. A path consists of slash separated segments, that
+// select child elements of the previous nodes starting with the top-level nodes of the file:
+//
+//	key   selects the values of tags with that key
+//	name  selects calls with that symbol as first element
+//	*     selects all elements, values for tags
+//	0     selects the element with that index
+//
+// For example 'schema/model/name' selects the names of all models in a schema and '0/fields/*'
+// all elements of the fields list of a top-level keyr.
+func (f *File) Select(path string) ([]*Match, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	root := &ast.Node{Seq: f.Nodes}
+	cur := []*Match{{Node: root, f: f}}
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" {
+			return nil, fmt.Errorf("empty segment in path %q", path)
+		}
+		var next []*Match
+		for _, m := range cur {
+			next = append(next, m.children(root, seg)...)
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func (m *Match) children(root *ast.Node, seg string) (res []*Match) {
+	path := m.Path
+	if m.Node != root {
+		path = append(path[:len(path):len(path)], m.Node)
+	}
+	idx := -1
+	if seg[0] >= '0' && seg[0] <= '9' {
+		if _, err := fmt.Sscanf(seg, "%d", &idx); err != nil {
+			return nil
+		}
+	}
+	for i, el := range m.Node.Seq {
+		switch {
+		case idx >= 0:
+			if i != idx {
+				continue
+			}
+		case seg == "*":
+		case el.Kind == knd.Tag:
+			if key, _ := tagKey(el); key != seg {
+				continue
+			}
+		case el.Kind == knd.Call:
+			if len(el.Seq) == 0 || el.Seq[0].Kind != knd.Sym || el.Seq[0].Raw != seg {
+				continue
+			}
+		default:
+			continue
+		}
+		if el.Kind == knd.Tag {
+			if len(el.Seq) < 2 {
+				continue
+			}
+			res = append(res, &Match{Node: el.Seq[1], Path: append(path, el), f: m.f})
+		} else {
+			res = append(res, &Match{Node: el, Path: path, f: m.f})
+		}
+	}
+	return res
+}
+
+// Replace replaces the matched node with the node of the source template tmpl.
+// Capture symbols in the template are replaced with copies of the captured nodes.
+func (m *Match) Replace(tmpl string) error {
+	n, err := m.template(tmpl)
+	if err != nil {
+		return err
+	}
+	return m.replace(n)
+}
+
+// ReplaceAst replaces the matched node with the ast a printed by the file formatter.
+func (m *Match) ReplaceAst(a ast.Ast) error {
+	n, err := m.f.format(a)
+	if err != nil {
+		return err
+	}
+	return m.replace(n)
+}
+
+func (m *Match) replace(n *ast.Node) error {
+	seq, i, err := m.locate(false)
+	if err != nil {
+		return err
+	}
+	setLead(n, leadOf(m.Node))
+	(*seq)[i] = n
+	m.Node = n
+	return nil
+}
+
+// Remove removes the matched node. Removing a tag value removes the tag.
+func (m *Match) Remove() error {
+	seq, i, err := m.locate(true)
+	if err != nil {
+		return err
+	}
+	s := *seq
+	if i == 0 && len(s) > 1 {
+		// the next element takes the place and the lead of the removed element
+		setLead(s[1], leadOf(s[0]))
+	}
+	*seq = append(s[:i], s[i+1:]...)
+	return nil
+}
+
+// InsertBefore inserts the node of the source template tmpl before the matched node.
+func (m *Match) InsertBefore(tmpl string) error { return m.insert(tmpl, 0) }
+
+// InsertAfter inserts the node of the source template tmpl after the matched node.
+func (m *Match) InsertAfter(tmpl string) error { return m.insert(tmpl, 1) }
+
+func (m *Match) insert(tmpl string, off int) error {
+	n, err := m.template(tmpl)
+	if err != nil {
+		return err
+	}
+	seq, i, err := m.locate(true)
+	if err != nil {
+		return err
+	}
+	s := *seq
+	if off == 0 {
+		setLead(n, leadOf(s[i]))
+		setLead(s[i], sepLead(s, i))
+	} else {
+		setLead(n, sepLead(s, i))
+	}
+	i += off
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = n
+	*seq = s
+	return nil
+}
+
+// Append appends the node of the source template tmpl to the elements of the matched tree.
+func (m *Match) Append(tmpl string) error {
+	t := m.Node
+	if t.Close == nil {
+		return fmt.Errorf("cannot append to %s", t.Ast())
+	}
+	n, err := m.template(tmpl)
+	if err != nil {
+		return err
+	}
+	if len(t.Seq) == 0 {
+		setLead(n, "")
+	} else {
+		setLead(n, sepLead(t.Seq, len(t.Seq)-1))
+	}
+	t.Seq = append(t.Seq, n)
+	return nil
+}
+
+// locate returns the sequence that holds the matched node and its index. Tag values are located
+// in their tag, unless tags is true, then the tag is located in its containing sequence.
+func (m *Match) locate(tags bool) (*[]*ast.Node, int, error) {
+	node, path := m.Node, m.Path
+	if n := len(path); n > 0 && path[n-1].Kind == knd.Tag && len(path[n-1].Seq) > 1 &&
+		path[n-1].Seq[1] == node {
+		if !tags {
+			return &path[n-1].Seq, 1, nil
+		}
+		node, path = path[n-1], path[:n-1]
+	}
+	seq := &m.f.Nodes
+	if n := len(path); n > 0 {
+		seq = &path[n-1].Seq
+	}
+	for i, el := range *seq {
+		if el == node {
+			return seq, i, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("matched node %s not found", node.Ast())
+}
+
+func (m *Match) template(tmpl string) (*ast.Node, error) {
+	n, err := parseNode(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Caps) > 0 {
+		n = expand(n, m.Caps)
+	}
+	return n, nil
+}
+
+func (f *File) format(a ast.Ast) (*ast.Node, error) {
+	fm := f.Fmt
+	if fm == nil {
+		fm = &ast.SimpleFormat{}
+	}
+	var b strings.Builder
+	if err := fm.Format(&b, a); err != nil {
+		return nil, err
+	}
+	return parseNode(b.String())
+}
+
+func parseNode(src string) (*ast.Node, error) {
+	f, err := ast.ReadFile(strings.NewReader(src), "template")
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nodes) != 1 {
+		return nil, fmt.Errorf("template must have exactly one node got %d", len(f.Nodes))
+	}
+	return f.Nodes[0], nil
+}
+
+// expand returns n with capture symbols replaced by copies of the captured nodes.
+func expand(n *ast.Node, caps Caps) *ast.Node {
+	if n.Kind == knd.Sym && isCap(n.Raw) {
+		if c := caps[n.Raw[1:]]; c != nil {
+			r := clone(c)
+			r.Lead = n.Lead
+			return r
+		}
+	}
+	for i, el := range n.Seq {
+		n.Seq[i] = expand(el, caps)
+	}
+	return n
+}
+
+func clone(n *ast.Node) *ast.Node {
+	r := *n
+	if n.Seq != nil {
+		r.Seq = make([]*ast.Node, len(n.Seq))
+		for i, el := range n.Seq {
+			r.Seq[i] = clone(el)
+		}
+	}
+	if n.Close != nil {
+		c := *n.Close
+		r.Close = &c
+	}
+	return &r
+}
+
+// leadOf returns the lead trivia printed before n, that is the key lead for tags.
+func leadOf(n *ast.Node) string {
+	if n.Kind == knd.Tag && len(n.Seq) > 0 {
+		return n.Seq[0].Lead
+	}
+	return n.Lead
+}
+
+func setLead(n *ast.Node, lead string) {
+	if n.Kind == knd.Tag && len(n.Seq) > 0 {
+		n.Seq[0].Lead = lead
+	} else {
+		n.Lead = lead
+	}
+}
+
+// sepLead returns the lead to separate a node from its predecessor in s near index i.
+// It uses the lead of the following or the element at i if not the first element.
+func sepLead(s []*ast.Node, i int) string {
+	if i+1 < len(s) {
+		if l := leadOf(s[i+1]); l != "" {
+			return l
+		}
+	}
+	if i > 0 {
+		if l := leadOf(s[i]); l != "" {
+			return l
+		}
+	}
+	return " "
+}
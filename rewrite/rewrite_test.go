@@ -0,0 +1,147 @@
+package rewrite
+
+import (
+	"strings"
+	"testing"
+
+	"xelf.org/xelf/ast"
+)
+
+const layout = `(form
+	(prod.Prod name:'a'  label:'A')
+	(group
+
+		(prod.Prod 'b' name:"b", x:1)
+		(prod.Item name:'c')
+	)
+)
+`
+
+func read(t *testing.T, src string) *File {
+	t.Helper()
+	f, err := Read(strings.NewReader(src), "layout.xelf")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return f
+}
+
+func TestFind(t *testing.T) {
+	f := read(t, layout)
+	tests := []struct {
+		pat  string
+		want []string
+	}{
+		{"(prod.Prod name:_)", []string{"(prod.Prod name:'a' label:'A')", `(prod.Prod 'b' name:"b" x:1)`}},
+		{"(prod.Prod 'b')", []string{`(prod.Prod 'b' name:"b" x:1)`}},
+		{"(_ name:'b')", []string{`(prod.Prod 'b' name:"b" x:1)`}},
+		{"(_ x;)", []string{`(prod.Prod 'b' name:"b" x:1)`}},
+		{"name:_", []string{"name:'a'", `name:"b"`, "name:'c'"}},
+		{"(prod.Item)", []string{"(prod.Item name:'c')"}},
+		{"(form (prod.Prod))", []string{layout[:len(layout)-1]}},
+		{"(prod.Prod label:'B')", nil},
+		{"(?a ?a)", nil},
+	}
+	for _, test := range tests {
+		ms := f.Find(MustCompile(test.pat))
+		var got []string
+		for _, m := range ms {
+			got = append(got, m.Node.Ast().String())
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("find %s want %q got %q", test.pat, test.want, got)
+			continue
+		}
+		for i, g := range got {
+			want := test.want[i]
+			if strings.Contains(want, "\n") {
+				a, _ := ast.Read(strings.NewReader(want), "")
+				want = a.String()
+			}
+			if g != want {
+				t.Errorf("find %s want %s got %s", test.pat, want, g)
+			}
+		}
+	}
+}
+
+func TestEdit(t *testing.T) {
+	f := read(t, layout)
+	for _, m := range f.Find(MustCompile("(prod.Prod name:?n)")) {
+		if err := m.Replace("(prod.Field key:?n)"); err != nil {
+			t.Fatalf("replace: %v", err)
+		}
+	}
+	ms, err := f.Select("form/group/prod.Item/name")
+	if err != nil || len(ms) != 1 {
+		t.Fatalf("select: %v %v", ms, err)
+	}
+	if err = ms[0].Replace("'d'"); err != nil {
+		t.Fatalf("replace value: %v", err)
+	}
+	ms, _ = f.Select("form/group")
+	if err = ms[0].Append("(prod.Item name:'e')"); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	ms = f.Find(MustCompile("(prod.Item name:'d')"))
+	if err = ms[0].InsertBefore("(sep)"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	ms, _ = f.Select("form/1")
+	if err = ms[0].InsertAfter("title"); err != nil {
+		t.Fatalf("insert after: %v", err)
+	}
+	ms, _ = f.Select("form/group")
+	if err = ms[0].ReplaceAst(ms[0].Node.Ast()); err != nil {
+		t.Fatalf("replace ast: %v", err)
+	}
+	want := `(form
+	(prod.Field key:'a')
+	title
+	(group (prod.Field key:"b") (sep) (prod.Item name:'d') (prod.Item name:'e'))
+)
+`
+	if got := f.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	f := read(t, layout)
+	ms, _ := f.Select("form/prod.Prod/label")
+	if err := ms[0].Remove(); err != nil {
+		t.Fatalf("remove tag: %v", err)
+	}
+	ms = f.Find(MustCompile("(prod.Prod 'b')"))
+	if err := ms[0].Remove(); err != nil {
+		t.Fatalf("remove call: %v", err)
+	}
+	ms, _ = f.Select("form/prod.Prod")
+	if err := ms[0].Remove(); err != nil {
+		t.Fatalf("remove first: %v", err)
+	}
+	want := `(form
+	(group
+		(prod.Item name:'c')
+	)
+)
+`
+	if got := f.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRemoveFirst(t *testing.T) {
+	f := read(t, "{a:1, b:2 c:[1 2]}")
+	ms, _ := f.Select("0/a")
+	if err := ms[0].Remove(); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	ms, _ = f.Select("0/c/0")
+	if err := ms[0].Remove(); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got, want := f.String(), "{b:2 c:[2]}"; got != want {
+		t.Errorf("want %s got %s", want, got)
+	}
+}
@@ -0,0 +1,143 @@
+package xps
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BuildInfo holds the go version and module versions of a go binary.
+type BuildInfo struct {
+	GoVersion string
+	// Mods maps module paths of the main module and all dependencies to their version.
+	// Replaced modules have the version followed by ' => ' and the replacement path and version.
+	Mods map[string]string
+}
+
+// HostInfo returns the build info of the running binary or an error.
+func HostInfo() (*BuildInfo, error) {
+	hostOnce.Do(func() {
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			hostErr = fmt.Errorf("no build info for host binary")
+			return
+		}
+		hostInfo = newBuildInfo(bi)
+	})
+	return hostInfo, hostErr
+}
+
+var (
+	hostOnce sync.Once
+	hostInfo *BuildInfo
+	hostErr  error
+)
+
+// ReadBuildInfo reads and returns the build info of the go binary at path.
+func ReadBuildInfo(path string) (*BuildInfo, error) {
+	bi, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read build info for %s: %v", path, err)
+	}
+	return newBuildInfo(bi), nil
+}
+
+func newBuildInfo(bi *debug.BuildInfo) *BuildInfo {
+	res := &BuildInfo{GoVersion: bi.GoVersion, Mods: make(map[string]string)}
+	res.add(&bi.Main)
+	for _, m := range bi.Deps {
+		res.add(m)
+	}
+	return res
+}
+
+func (bi *BuildInfo) add(m *debug.Module) {
+	if m == nil || m.Path == "" {
+		return
+	}
+	ver := m.Version
+	if r := m.Replace; r != nil {
+		ver = replaced(ver, r.Path, r.Version)
+	}
+	bi.Mods[m.Path] = ver
+}
+
+func replaced(ver, path, rver string) string {
+	if rver != "" {
+		path += " " + rver
+	}
+	return ver + " => " + path
+}
+
+// Mismatch is a module with different versions in host and plugin.
+// The go version is reported as module with path 'go'.
+type Mismatch struct {
+	Path string
+	Host string
+	Plug string
+}
+
+// Compare returns all mismatched versions of modules used by both host and plug sorted by path.
+// Modules without a comparable version, like a development version or a local replacement without
+// version, are skipped because they are only detected when the plugin is opened.
+func Compare(host, plug *BuildInfo) (res []Mismatch) {
+	if host.GoVersion != plug.GoVersion {
+		res = append(res, Mismatch{"go", host.GoVersion, plug.GoVersion})
+	}
+	for path, hv := range host.Mods {
+		pv, ok := plug.Mods[path]
+		if !ok || hv == pv || !hasVersion(hv) || !hasVersion(pv) {
+			continue
+		}
+		res = append(res, Mismatch{path, hv, pv})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
+
+func hasVersion(ver string) bool {
+	if ver == "" || ver == "(devel)" {
+		return false
+	}
+	if idx := strings.Index(ver, " => "); idx >= 0 {
+		// local replacements have no version
+		return strings.IndexByte(ver[idx+4:], ' ') > 0
+	}
+	return true
+}
+
+// ABIError is returned for plugins that were built with mismatched module versions.
+type ABIError struct {
+	Plug       string
+	Mismatches []Mismatch
+}
+
+func (e *ABIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "plugin %s was built with different module versions:", e.Plug)
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(&b, "\n\t%s host %s plugin %s", m.Path, m.Host, m.Plug)
+	}
+	return b.String()
+}
+
+// CheckABI compares the build info of the host and plugin binary and returns an ABIError for
+// mismatched module versions or any other error.
+func (p *Plug) CheckABI() error {
+	host, err := HostInfo()
+	if err != nil {
+		return err
+	}
+	path := p.PlugPath()
+	plug, err := ReadBuildInfo(path)
+	if err != nil {
+		return err
+	}
+	if ms := Compare(host, plug); len(ms) > 0 {
+		return &ABIError{Plug: path, Mismatches: ms}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package xps
+
+import (
+	"os"
+	"reflect"
+	"runtime/debug"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	plug := newBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.21.0",
+		Path:      "example.com/plug",
+		Main:      debug.Module{Path: "example.com/plug", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/mb0/diff", Version: "v0.0.0-20131118162322-d8d9a906c24d"},
+			{Path: "example.com/util", Version: "v1.2.0"},
+			{Path: "xelf.org/xelf", Version: "v0.1.0", Replace: &debug.Module{Path: "../xelf"}},
+		},
+	})
+	want := &BuildInfo{GoVersion: "go1.21.0", Mods: map[string]string{
+		"example.com/plug":    "(devel)",
+		"github.com/mb0/diff": "v0.0.0-20131118162322-d8d9a906c24d",
+		"example.com/util":    "v1.2.0",
+		"xelf.org/xelf":       "v0.1.0 => ../xelf",
+	}}
+	if !reflect.DeepEqual(plug, want) {
+		t.Errorf("want build info %v got %v", want, plug)
+	}
+	host := &BuildInfo{GoVersion: "go1.21.1", Mods: map[string]string{
+		"xelf.org/xelf":       "(devel)",
+		"github.com/mb0/diff": "v0.0.0-20131118162322-d8d9a906c24d",
+		"example.com/util":    "v1.3.0 => example.com/fork v1.3.1",
+	}}
+	got := Compare(host, plug)
+	wantMs := []Mismatch{
+		{"example.com/util", "v1.3.0 => example.com/fork v1.3.1", "v1.2.0"},
+		{"go", "go1.21.1", "go1.21.0"},
+	}
+	if !reflect.DeepEqual(got, wantMs) {
+		t.Errorf("want mismatches %v got %v", wantMs, got)
+	}
+}
+
+func TestReadBuildInfo(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	bi, err := ReadBuildInfo(exe)
+	if err != nil {
+		t.Fatalf("read build info: %v", err)
+	}
+	host, err := HostInfo()
+	if err != nil {
+		t.Fatalf("host info: %v", err)
+	}
+	if !reflect.DeepEqual(bi, host) {
+		t.Errorf("want build info %v got %v", host, bi)
+	}
+}
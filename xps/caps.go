@@ -0,0 +1,201 @@
+package xps
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Cap describes a plugin capability kind that can be declared in the manifest caps.
+type Cap struct {
+	// Name is the key of the capability in the manifest caps.
+	Name string
+	// Type is the expected value type, capability values are converted to it on validation.
+	Type typ.Type
+	// Keys returns the keys provided by a converted capability value, like module paths or
+	// command names. It defaults to DefaultKeys.
+	Keys func(lit.Val) ([]string, error)
+}
+
+var capReg = struct {
+	sync.RWMutex
+	m map[string]Cap
+}{m: make(map[string]Cap)}
+
+func init() {
+	MustRegisterCap(Cap{Name: "mods", Type: typ.ListOf(typ.Str)})
+	MustRegisterCap(Cap{Name: "cmds", Type: typ.DictOf(typ.Str)})
+}
+
+// RegisterCap registers the capability kind c or returns an error if the name is already used.
+func RegisterCap(c Cap) error {
+	if c.Name == "" {
+		return fmt.Errorf("capability without name")
+	}
+	capReg.Lock()
+	defer capReg.Unlock()
+	if _, ok := capReg.m[c.Name]; ok {
+		return fmt.Errorf("capability %s already registered", c.Name)
+	}
+	capReg.m[c.Name] = c
+	return nil
+}
+
+// MustRegisterCap registers the capability kind c or panics.
+func MustRegisterCap(c Cap) {
+	if err := RegisterCap(c); err != nil {
+		panic(err)
+	}
+}
+
+// LookupCap returns the registered capability kind with name and whether it was found.
+func LookupCap(name string) (Cap, bool) {
+	capReg.RLock()
+	defer capReg.RUnlock()
+	c, ok := capReg.m[name]
+	return c, ok
+}
+
+// RegisteredCaps returns all registered capability kinds sorted by name.
+func RegisteredCaps() []Cap {
+	capReg.RLock()
+	res := make([]Cap, 0, len(capReg.m))
+	for _, c := range capReg.m {
+		res = append(res, c)
+	}
+	capReg.RUnlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// DefaultKeys returns the elements of list values, the keys of dict values or the string of
+// character values as capability keys.
+func DefaultKeys(v lit.Val) (res []string, err error) {
+	switch a := lit.Unwrap(v).(type) {
+	case lit.Keyr:
+		return a.Keys(), nil
+	case lit.Idxr:
+		err = a.IterIdx(func(_ int, el lit.Val) error {
+			res = append(res, el.String())
+			return nil
+		})
+		return res, err
+	}
+	if v.Type().Kind&knd.Char != 0 {
+		return []string{v.String()}, nil
+	}
+	return nil, fmt.Errorf("no capability keys for %s", v.Type())
+}
+
+// CapKeys returns the keys the manifest provides for capability name or nil.
+func (m Manifest) CapKeys(name string) []string {
+	v, err := m.Caps.Key(name)
+	if err != nil || v == nil || v.Nil() {
+		return nil
+	}
+	keys, _ := capKeys(name, v)
+	return keys
+}
+
+// Provides returns whether the manifest provides capability name for key.
+func (m Manifest) Provides(name, key string) bool {
+	for _, k := range m.CapKeys(name) {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Provider returns the first manifest in ms that provides capability name for key or nil.
+func Provider(ms []Manifest, name, key string) *Manifest {
+	for i := range ms {
+		if ms[i].Provides(name, key) {
+			return &ms[i]
+		}
+	}
+	return nil
+}
+
+// Provider returns the first plugin that provides capability name for key or nil.
+func (ps *Plugs) Provider(name, key string) *Plug {
+	if m := Provider(ps.Mani, name, key); m != nil {
+		return ps.All[m.Path]
+	}
+	return nil
+}
+
+// Validate checks the manifest name, exec command and all registered capabilities.
+func (m Manifest) Validate() error { return m.validate(nil) }
+
+func (m Manifest) validate(a *ast.Ast) error {
+	if m.Name == "" {
+		return errManifest(m.src(a), "name", fmt.Errorf("name is required"))
+	}
+	for i, arg := range m.Exec {
+		if arg == "" {
+			return errManifest(m.src(a, "exec"), "exec", fmt.Errorf("empty argument %d", i))
+		}
+	}
+	for _, kv := range m.Caps {
+		if _, ok := LookupCap(kv.Key); !ok {
+			// unknown capabilities are not validated
+			continue
+		}
+		if _, err := capKeys(kv.Key, kv.Val); err != nil {
+			return errManifest(m.src(a, "caps", kv.Key), "cap "+kv.Key, err)
+		}
+	}
+	return nil
+}
+
+func capKeys(name string, v lit.Val) ([]string, error) {
+	c, ok := LookupCap(name)
+	if !ok {
+		return DefaultKeys(v)
+	}
+	if c.Type != typ.Void {
+		cv, err := v.As(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		v = cv
+	}
+	if c.Keys != nil {
+		return c.Keys(v)
+	}
+	return DefaultKeys(v)
+}
+
+// src returns the source of the value at the key path in the manifest ast a or the closest parent.
+func (m Manifest) src(a *ast.Ast, path ...string) ast.Src {
+	if a == nil {
+		return ast.Src{Doc: &ast.Doc{Name: m.Path}}
+	}
+	res := a.Src
+Path:
+	for _, key := range path {
+		if a.Kind != knd.Keyr {
+			break
+		}
+		for i := range a.Seq {
+			el := &a.Seq[i]
+			if el.Kind == knd.Tag && len(el.Seq) > 1 && el.Seq[0].Raw == key {
+				a = &el.Seq[1]
+				res = a.Src
+				continue Path
+			}
+		}
+		break
+	}
+	return res
+}
+
+func errManifest(src ast.Src, name string, err error) error {
+	return &ast.Error{Src: src, Code: 403, Name: "invalid manifest " + name, Err: err}
+}
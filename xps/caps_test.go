@@ -0,0 +1,86 @@
+package xps
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"xelf.org/xelf/typ"
+)
+
+func TestManifestCaps(t *testing.T) {
+	if _, ok := LookupCap("fmts"); !ok {
+		MustRegisterCap(Cap{Name: "fmts", Type: typ.DictOf(typ.Str)})
+	}
+	if err := RegisterCap(Cap{Name: "mods"}); err == nil {
+		t.Errorf("want error for duplicate capability")
+	}
+	dir, err := ioutil.TempDir("", "xps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name string
+		raw  string
+		err  string
+	}{
+		{"a.so.xelf", "{name:'a' caps:{mods:['a/one' 'a/two'] fmts:{csv:'text/csv'} misc:true}}", ""},
+		{"b.so.xelf", "{name:'b' caps:{mods:['b/one'] fmts:{csv:'text/csv' tsv:'text/tsv'}}}", ""},
+		{"c.so.xelf", "{caps:{}}", "c.so.xelf:1:0: invalid manifest name E403"},
+		{"d.so.xelf", "{name:'d'\ncaps:{mods:[1 2]}}", "d.so.xelf:2:12: invalid manifest cap mods E403"},
+		{"e.so.xelf", "{name:'e'\ncaps:{fmts:['csv']}}", "e.so.xelf:2:12: invalid manifest cap fmts E403"},
+	}
+	var ms []Manifest
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := ioutil.WriteFile(path, []byte(test.raw), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m, err := Read(path)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("read %s: %v", test.name, err)
+			}
+			ms = append(ms, m)
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), filepath.Join(dir, test.err)) {
+			t.Errorf("read %s: want error %s got %v", test.name, test.err, err)
+		}
+	}
+	if len(ms) != 2 {
+		t.Fatalf("want two valid manifests got %d", len(ms))
+	}
+	if got, want := ms[0].Mods(), []string{"a/one", "a/two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mods want %v got %v", want, got)
+	}
+	if got, want := ms[1].CapKeys("fmts"), []string{"csv", "tsv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fmts want %v got %v", want, got)
+	}
+	ps := &Plugs{}
+	ps.Init(ms)
+	queries := []struct {
+		cap, key string
+		want     string
+	}{
+		{"mods", "a/two", "a"},
+		{"mods", "b/one", "b"},
+		{"fmts", "csv", "a"},
+		{"fmts", "tsv", "b"},
+		{"fmts", "json", ""},
+		{"cmds", "a", ""},
+	}
+	for _, q := range queries {
+		var got string
+		if p := ps.Provider(q.cap, q.key); p != nil {
+			got = p.Name
+		}
+		if got != q.want {
+			t.Errorf("provider %s %s want %q got %q", q.cap, q.key, q.want, got)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package xps
 
 import (
 	"fmt"
+	"io"
 
 	"xelf.org/xelf/exp"
 )
@@ -20,6 +21,8 @@ type CmdCtx struct {
 	Plugs
 	Dir  string
 	Args []string
+	// Out receives the command output of exec plugins, nil defaults to stdout.
+	Out io.Writer
 
 	Wrap func(*CmdCtx, exp.Env) exp.Env
 	Prog func(*CmdCtx) *exp.Prog
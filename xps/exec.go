@@ -0,0 +1,282 @@
+package xps
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"xelf.org/xelf/ast"
+	"xelf.org/xelf/cor"
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/knd"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/mod"
+	"xelf.org/xelf/typ"
+)
+
+// The exec plugin protocol uses json messages separated by newlines over the plugin process stdio.
+// The host sends a request and waits for the response with the same id. The methods are:
+//
+//	init  result InitResult with the plugin name and func spec signatures
+//	src   params SrcParams, result SrcResult with the module source url and raw xelf source
+//	call  params CallParams, result the json value returned by the spec
+//	cmd   params CmdParams, result CmdResult with the command output
+//
+// Func specs declared by the plugin are available to the module sources it provides.
+
+// Request is an exec plugin protocol request.
+type Request struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is an exec plugin protocol response with either a result or an error message.
+type Response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type (
+	InitResult struct {
+		Name  string            `json:"name"`
+		Specs map[string]string `json:"specs,omitempty"`
+	}
+	SrcParams struct {
+		Path string `json:"path"`
+	}
+	SrcResult struct {
+		URL string `json:"url"`
+		Raw string `json:"raw"`
+	}
+	CallParams struct {
+		Spec string            `json:"spec"`
+		Args []json.RawMessage `json:"args"`
+	}
+	CmdParams struct {
+		Dir  string   `json:"dir"`
+		Args []string `json:"args"`
+	}
+	CmdResult struct {
+		Out string `json:"out"`
+	}
+)
+
+// ExecPlug is a running plugin process that talks the exec plugin protocol.
+type ExecPlug struct {
+	Name  string
+	Specs exp.Builtins
+
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	in   io.WriteCloser
+	out  *bufio.Reader
+	last int64
+}
+
+// StartExec starts the plugin command args, sends the init request and returns the plugin or an
+// error. The process is stopped when the returned plugin is closed.
+func StartExec(dir string, args ...string) (*ExecPlug, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("exec plugin without command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	e := &ExecPlug{cmd: cmd, in: in, out: bufio.NewReader(out)}
+	var res InitResult
+	if err = e.Send("init", nil, &res); err != nil {
+		e.Close()
+		return nil, err
+	}
+	e.Name = res.Name
+	e.Specs = make(exp.Builtins, len(res.Specs))
+	for name, sig := range res.Specs {
+		t, err := typ.Parse(sig)
+		if err != nil || t.Kind&knd.Spec != knd.Func {
+			e.Close()
+			return nil, fmt.Errorf("exec plugin %s spec %s: invalid func signature %s", e.Name, name, sig)
+		}
+		t.Ref = name
+		e.Specs[name] = &execSpec{exp.SpecBase{Decl: t}, e}
+	}
+	return e, nil
+}
+
+// Send sends a request with method and params and decodes the result into res or returns an error.
+func (e *ExecPlug) Send(method string, params, res interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.last++
+	req := Request{ID: e.last, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err = e.in.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("exec plugin %s %s: %v", e.Name, method, err)
+	}
+	line, err := e.out.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("exec plugin %s %s: %v", e.Name, method, err)
+	}
+	var resp Response
+	if err = json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("exec plugin %s %s: %v", e.Name, method, err)
+	}
+	if resp.ID != req.ID {
+		return fmt.Errorf("exec plugin %s %s: want response %d got %d", e.Name, method, req.ID, resp.ID)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("exec plugin %s %s: %s", e.Name, method, resp.Error)
+	}
+	if res != nil {
+		return json.Unmarshal(resp.Result, res)
+	}
+	return nil
+}
+
+// Src requests and returns the module source for path or an error.
+// The source is evaluated in an environment that provides the plugin specs.
+func (e *ExecPlug) Src(path string) (*mod.Src, error) {
+	var res SrcResult
+	if err := e.Send("src", SrcParams{Path: path}, &res); err != nil {
+		return nil, err
+	}
+	if res.URL == "" {
+		res.URL = "xelf:" + path
+	}
+	as, err := ast.ReadAll(strings.NewReader(res.Raw), res.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &mod.Src{Rel: path, Loc: mod.Loc{URL: res.URL}, Raw: as, Setup: e.setup}, nil
+}
+
+func (e *ExecPlug) setup(prog *exp.Prog, src *mod.Src) (*exp.File, error) {
+	x, err := exp.ParseAll(src.Raw)
+	if err != nil {
+		return nil, err
+	}
+	p := *prog
+	p.File = exp.File{URL: src.URL}
+	p.Root = &specEnv{Par: prog.Root, Specs: e.Specs}
+	x, err = p.Resl(&p, x, typ.Void)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = p.Eval(&p, x); err != nil {
+		return nil, err
+	}
+	return &p.File, nil
+}
+
+// Cmd runs the plugin command and writes the output to the command context out or stdout.
+func (e *ExecPlug) Cmd(ctx *CmdCtx) error {
+	var res CmdResult
+	err := e.Send("cmd", CmdParams{Dir: ctx.Dir, Args: ctx.Args}, &res)
+	if err != nil {
+		return err
+	}
+	w := ctx.Out
+	if w == nil {
+		w = os.Stdout
+	}
+	_, err = io.WriteString(w, res.Out)
+	return err
+}
+
+// ExecCloseTimeout is the time exec plugins have to exit after close before they are killed.
+var ExecCloseTimeout = 5 * time.Second
+
+// Close stops the plugin process and returns an error if it did not exit cleanly.
+// Processes that do not exit within the ExecCloseTimeout are killed.
+func (e *ExecPlug) Close() error {
+	e.in.Close()
+	done := make(chan error, 1)
+	go func() { done <- e.cmd.Wait() }()
+	t := time.NewTimer(ExecCloseTimeout)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-t.C:
+		e.cmd.Process.Kill()
+		<-done
+		return fmt.Errorf("exec plugin %s did not exit and was killed", e.Name)
+	}
+}
+
+// execSpec is a func spec that is evaluated by an exec plugin.
+type execSpec struct {
+	exp.SpecBase
+	plug *ExecPlug
+}
+
+func (s *execSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	args, err := p.EvalArgs(c)
+	if err != nil {
+		return nil, err
+	}
+	ps := CallParams{Spec: s.Decl.Ref, Args: make([]json.RawMessage, len(args))}
+	for i, a := range args {
+		if a == nil {
+			a = lit.Null{}
+		}
+		if ps.Args[i], err = a.MarshalJSON(); err != nil {
+			return nil, err
+		}
+	}
+	var res json.RawMessage
+	if err = s.plug.Send("call", ps, &res); err != nil {
+		return nil, err
+	}
+	val, err := lit.Read(strings.NewReader(string(res)), s.Decl.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if rt := exp.SigRes(c.Sig).Type; rt != typ.Any && rt.Kind&knd.Var == 0 {
+		return val.As(rt)
+	}
+	return val, nil
+}
+
+// specEnv resolves plugin specs before looking up symbols in the parent env.
+type specEnv struct {
+	Par   exp.Env
+	Specs exp.Builtins
+}
+
+func (e *specEnv) Parent() exp.Env { return e.Par }
+func (e *specEnv) Lookup(s *exp.Sym, p cor.Path, eval bool) (lit.Val, error) {
+	v, err := e.Specs.Lookup(s, p, eval)
+	if err != exp.ErrSymNotFound {
+		return v, err
+	}
+	return e.Par.Lookup(s, p, eval)
+}
@@ -0,0 +1,130 @@
+package xps
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/mod"
+)
+
+type shoutSpec struct{ exp.SpecBase }
+
+func (s *shoutSpec) Eval(p *exp.Prog, c *exp.Call) (lit.Val, error) {
+	args, err := p.EvalArgs(c)
+	if err != nil {
+		return nil, err
+	}
+	return lit.Str(strings.ToUpper(args[0].String())), nil
+}
+
+// TestExecHelper is not a real test but serves as exec plugin when run by TestExecPlug.
+func TestExecHelper(t *testing.T) {
+	if os.Getenv("XPS_EXEC_HELPER") != "1" {
+		return
+	}
+	s := &Server{Name: "loud",
+		Srcs:  map[string]string{"loud/mod": `(module loud hi:(shout 'hello') greet:(fn n:str (cat 'hi ' .n)))`},
+		Specs: map[string]exp.Spec{"shout": &shoutSpec{exp.MustSpecBase("<func@shout s:str str>")}},
+		Cmd: func(dir string, args []string, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "loud %s\n", strings.Join(args, " "))
+			return err
+		},
+	}
+	if err := s.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestExecPlug(t *testing.T) {
+	os.Setenv("XPS_EXEC_HELPER", "1")
+	defer os.Unsetenv("XPS_EXEC_HELPER")
+	dir, err := ioutil.TempDir("", "xps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mani := fmt.Sprintf("{name:'loud' exec:[%q '-test.run=^TestExecHelper$'] "+
+		"caps:{mods:['loud/mod'] cmds:{loud:'says things'}}}", os.Args[0])
+	err = ioutil.WriteFile(filepath.Join(dir, "loud.exe.xelf"), []byte(mani), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err := FindAll([]string{dir})
+	if err != nil || len(ms) != 1 {
+		t.Fatalf("find all: %v %v", ms, err)
+	}
+	ps := &Plugs{}
+	ps.Init(ms)
+	defer ps.Close()
+	env := mod.NewLoaderEnv(exp.Builtins(lib.Std), &ModLoader{Sys: new(mod.SysMods), Plugs: ps})
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`(import 'loud/mod') loud.hi`, `HELLO`},
+		{`(import 'loud/mod') (loud.greet 'you')`, `hi you`},
+	}
+	for _, test := range tests {
+		res, err := exp.NewProg(env).RunStr(test.raw, nil)
+		if err != nil {
+			t.Errorf("run %s: %v", test.raw, err)
+			continue
+		}
+		if got := res.String(); got != test.want {
+			t.Errorf("run %s: want %s got %s", test.raw, test.want, got)
+		}
+	}
+	p := ps.All[ms[0].Path]
+	if p.Exec == nil {
+		t.Fatalf("exec plugin not started")
+	}
+	if _, err = p.Exec.Src("loud/other"); err == nil {
+		t.Errorf("want error for unknown module source")
+	}
+	var out strings.Builder
+	err = p.Cmd(&CmdCtx{Dir: dir, Args: []string{"loud", "test"}, Out: &out})
+	if err != nil || out.String() != "loud loud test\n" {
+		t.Errorf("cmd: want output got %q %v", out.String(), err)
+	}
+	if err = p.Exec.Send("nope", nil, nil); err == nil {
+		t.Errorf("want error for unknown method")
+	}
+}
+
+func TestExecClose(t *testing.T) {
+	path, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip(err)
+	}
+	cmd := exec.Command(path, "10")
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func(d time.Duration) { ExecCloseTimeout = d }(ExecCloseTimeout)
+	ExecCloseTimeout = 10 * time.Millisecond
+	e := &ExecPlug{Name: "sleep", cmd: cmd, in: in}
+	start := time.Now()
+	err = e.Close()
+	if err == nil || !strings.Contains(err.Error(), "killed") {
+		t.Errorf("want killed error got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("close took %s", d)
+	}
+}
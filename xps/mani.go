@@ -1,4 +1,5 @@
-// Package xps provides helper and conventions for working with the go plugin system
+// Package xps provides helper and conventions for working with the go plugin system and exec
+// plugins, that run as separate process.
 package xps
 
 import (
@@ -7,7 +8,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"xelf.org/xelf/ast"
 	"xelf.org/xelf/lit"
 )
 
@@ -34,17 +37,28 @@ type Manifest struct {
 	// mods should be a list of provided modules
 	// cmds a dict with command keys and help values
 	Caps lit.Keyed `json:"caps,omitempty"`
+	// Exec holds the command and arguments of an exec plugin, that uses the stdio protocol.
+	// A relative command path is resolved from the manifest directory.
+	Exec []string `json:"exec,omitempty"`
 }
 
-// Read reads and returns the plugin manifest for path or an error.
+// Read reads, validates and returns the plugin manifest for path or an error.
+// Validation errors point to the manifest source position of the invalid value.
 func Read(path string) (m Manifest, err error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return m, err
 	}
-	err = lit.ReadInto(bytes.NewReader(b), path, lit.MustProxy(xpsReg, &m))
+	a, err := ast.Read(bytes.NewReader(b), path)
+	if err != nil {
+		return m, err
+	}
+	err = lit.MustProxy(xpsReg, &m).Parse(a)
 	m.Path = path
-	return m, err
+	if err != nil {
+		return m, err
+	}
+	return m, m.validate(&a)
 }
 
 func (m Manifest) String() string { return m.Path }
@@ -54,6 +68,18 @@ func (m Manifest) PlugPath() string {
 	}
 	return ""
 }
+
+// ExecArgs returns the exec plugin command and arguments or nil.
+func (m Manifest) ExecArgs() []string {
+	if len(m.Exec) == 0 {
+		return nil
+	}
+	res := append([]string(nil), m.Exec...)
+	if cmd := res[0]; !filepath.IsAbs(cmd) && strings.ContainsRune(cmd, filepath.Separator) {
+		res[0] = filepath.Join(filepath.Dir(m.Path), cmd)
+	}
+	return res
+}
 func (m Manifest) Cmds() lit.Keyed {
 	v, _ := m.Caps.Key("cmds")
 	if cmds, ok := v.(*lit.Keyed); ok {
@@ -61,7 +87,7 @@ func (m Manifest) Cmds() lit.Keyed {
 	}
 	return nil
 }
-func (m Manifest) Mods() []string { return m.CapList("mods") }
+func (m Manifest) Mods() []string { return m.CapKeys("mods") }
 func (m Manifest) CapList(key string) []string {
 	v, _ := m.Caps.Key(key)
 	if vs, ok := v.(*lit.Vals); ok {
@@ -89,7 +115,7 @@ func findAll(root string, res []Manifest) ([]Manifest, error) {
 			}
 			return nil
 		}
-		if len(n) > 8 && n[0] != '.' && n[len(n)-8:] == ".so.xelf" {
+		if len(n) > 8 && n[0] != '.' && (strings.HasSuffix(n, ".so.xelf") || strings.HasSuffix(n, ".exe.xelf")) {
 			m, err := Read(filepath.Join(root, path))
 			if err != nil {
 				return err
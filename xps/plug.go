@@ -1,24 +1,33 @@
 package xps
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"plugin"
 
 	"xelf.org/xelf/mod"
 )
 
 // Plug wraps a go plugin with a manifest. The manifest must use the '.so.xelf' file extension,
-// thereby encoding the expected location of the plugin binary.
+// thereby encoding the expected location of the plugin binary. Exec plugins use the '.exe.xelf'
+// extension and a manifest exec command, they are started as process on load.
 type Plug struct {
 	Manifest
 	*plugin.Plugin
-	Cmd Cmd
+	Exec *ExecPlug
+	Cmd  Cmd
+	ps   *Plugs
 }
 
 // Plugs caches and lazy-loads plugins.
 type Plugs struct {
 	Mani []Manifest
 	All  map[string]*Plug
+	// Check enables module version checks of host and plugin before plugins are loaded.
+	Check bool
+	// Rebuild enables rebuilding plugins with source that failed the version check.
+	Rebuild bool
 }
 
 func (ps *Plugs) Init(ms []Manifest) {
@@ -30,7 +39,7 @@ func (ps *Plugs) Init(ms []Manifest) {
 			continue // we already have that path
 		}
 		ps.Mani = append(ps.Mani, m)
-		ps.All[m.Path] = &Plug{Manifest: m}
+		ps.All[m.Path] = &Plug{Manifest: m, ps: ps}
 	}
 }
 
@@ -39,6 +48,20 @@ func (ps *Plugs) LoadPlug(path string) (p *Plug, err error) {
 	return p, p.ensure()
 }
 
+// Close stops all running exec plugins and returns the first error.
+func (ps *Plugs) Close() (err error) {
+	for _, p := range ps.All {
+		if p.Exec == nil {
+			continue
+		}
+		if cerr := p.Exec.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		p.Exec = nil
+	}
+	return err
+}
+
 // LoadCmd loads and returns a subcommand for the plugin name or nil.
 // It only returns an error if a plugin was found but could not load a command and nil otherwise.
 func (ps *Plugs) LoadCmd(name string) (Cmd, error) {
@@ -57,9 +80,31 @@ func (ps *Plugs) LoadCmd(name string) (Cmd, error) {
 	return nil, nil
 }
 
+// Load opens the plugin and looks up the command if the manifest has cmds capabilities.
+// If the plugin list has checks enabled the module versions are checked and if enabled plugins
+// with source are rebuilt on mismatch. Errors opening a plugin report all version mismatches.
+// Exec plugins are started instead and provide their command over the plugin protocol.
 func (p *Plug) Load() (err error) {
+	if args := p.ExecArgs(); len(args) > 0 {
+		p.Exec, err = StartExec(filepath.Dir(p.Path), args...)
+		if err != nil {
+			return err
+		}
+		if len(p.Cmds()) > 0 {
+			p.Cmd = p.Exec.Cmd
+		}
+		return nil
+	}
+	if p.ps != nil && p.ps.Check {
+		if err = p.check(p.ps.Rebuild); err != nil {
+			return err
+		}
+	}
 	p.Plugin, err = plugin.Open(p.PlugPath())
 	if err != nil {
+		if cerr := p.CheckABI(); cerr != nil {
+			return fmt.Errorf("%v\n%v", err, cerr)
+		}
 		return err
 	}
 	if len(p.Cmds()) > 0 {
@@ -72,18 +117,33 @@ func (p *Plug) Load() (err error) {
 	return nil
 }
 
+func (p *Plug) check(rebuild bool) error {
+	err := p.CheckABI()
+	var ae *ABIError
+	if err == nil || !rebuild || !errors.As(err, &ae) || !HasSource(p.Manifest) {
+		return err
+	}
+	if err = Rebuild(p.Manifest); err != nil {
+		return fmt.Errorf("rebuild plugin %s: %v", p.PlugPath(), err)
+	}
+	return p.CheckABI()
+}
+
 func (p *Plug) ensure() error {
 	if p == nil {
 		return fmt.Errorf("plugin not found")
 	}
-	if p.Plugin == nil {
+	if !p.loaded() {
 		return p.Load()
 	}
 	return nil
 }
 
+func (p *Plug) loaded() bool { return p.Plugin != nil || p.Exec != nil }
+
 // ModLoader wrapps a SysMods module source registry with a plugin list.
 // It lazy-loads plugins that provide module source missing from the registry.
+// Module sources of exec plugins are requested from the plugin and added to the registry.
 type ModLoader struct {
 	Sys *mod.SysMods
 	*Plugs
@@ -95,24 +155,21 @@ func (l *ModLoader) LoadSrc(raw, base *mod.Loc) (*mod.Src, error) {
 	}
 	src, err := l.Sys.LoadSrc(raw, base)
 	if err != nil {
-		p := modPlug(l.All, raw.Path())
-		if p != nil && p.Plugin == nil {
+		p := l.Provider("mods", raw.Path())
+		if p != nil && !p.loaded() {
 			if err = p.Load(); err != nil {
 				return nil, err
 			}
-			src, err = l.Sys.LoadSrc(raw, base)
+			if p.Exec == nil {
+				src, err = l.Sys.LoadSrc(raw, base)
+			}
 		}
-	}
-	return src, err
-}
-
-func modPlug(all map[string]*Plug, mpath string) *Plug {
-	for _, p := range all {
-		for _, path := range p.Mods() {
-			if mpath == path {
-				return p
+		if p != nil && p.Exec != nil {
+			if src, err = p.Exec.Src(raw.Path()); err != nil {
+				return nil, err
 			}
+			return l.Sys.Register(src), nil
 		}
 	}
-	return nil
+	return src, err
 }
@@ -0,0 +1,133 @@
+package xps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"xelf.org/xelf/exp"
+	"xelf.org/xelf/lib"
+	"xelf.org/xelf/lit"
+	"xelf.org/xelf/typ"
+)
+
+// Server implements the plugin side of the exec plugin protocol.
+type Server struct {
+	Name string
+	// Srcs maps module paths to raw xelf module sources.
+	Srcs map[string]string
+	// Specs holds the func specs that can be called by the host and used in module sources.
+	Specs map[string]exp.Spec
+	// Env is the environment used to evaluate spec calls and defaults to the std builtins.
+	Env exp.Env
+	// Cmd is called for subcommands with the host working dir and arguments.
+	Cmd func(dir string, args []string, w io.Writer) error
+}
+
+// Serve reads requests from r and writes responses to w until r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<24)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		var req Request
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			return fmt.Errorf("exec plugin %s: %v", s.Name, err)
+		}
+		res, err := s.handle(req)
+		resp := Response{ID: req.ID}
+		if err != nil {
+			resp.Error = err.Error()
+		} else if resp.Result, err = json.Marshal(res); err != nil {
+			resp.Error = err.Error()
+		}
+		if err = enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func (s *Server) handle(req Request) (interface{}, error) {
+	switch req.Method {
+	case "init":
+		res := InitResult{Name: s.Name, Specs: make(map[string]string, len(s.Specs))}
+		for name, sp := range s.Specs {
+			res.Specs[name] = sp.Type().String()
+		}
+		return res, nil
+	case "src":
+		var ps SrcParams
+		if err := json.Unmarshal(req.Params, &ps); err != nil {
+			return nil, err
+		}
+		raw, ok := s.Srcs[ps.Path]
+		if !ok {
+			return nil, fmt.Errorf("module source %s not found", ps.Path)
+		}
+		return SrcResult{URL: "xelf:" + ps.Path, Raw: raw}, nil
+	case "call":
+		var ps CallParams
+		if err := json.Unmarshal(req.Params, &ps); err != nil {
+			return nil, err
+		}
+		return s.call(ps)
+	case "cmd":
+		var ps CmdParams
+		if err := json.Unmarshal(req.Params, &ps); err != nil {
+			return nil, err
+		}
+		if s.Cmd == nil {
+			return nil, fmt.Errorf("plugin %q has no command", s.Name)
+		}
+		var b strings.Builder
+		if err := s.Cmd(ps.Dir, ps.Args, &b); err != nil {
+			return nil, err
+		}
+		return CmdResult{Out: b.String()}, nil
+	}
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
+
+func (s *Server) call(ps CallParams) (json.RawMessage, error) {
+	sp := s.Specs[ps.Spec]
+	if sp == nil {
+		return nil, fmt.Errorf("spec %s not found", ps.Spec)
+	}
+	env := s.Env
+	if env == nil {
+		env = exp.Builtins(lib.Std)
+	}
+	p := exp.NewProg(env)
+	sig, err := p.Sys.Inst(exp.LookupType(env), sp.Type())
+	if err != nil {
+		return nil, err
+	}
+	args := make([]exp.Exp, 0, len(ps.Args))
+	for _, raw := range ps.Args {
+		v, err := lit.Read(bytes.NewReader(raw), ps.Spec)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &exp.Lit{Val: v})
+	}
+	args, err = exp.LayoutSpec(sig, args)
+	if err != nil {
+		return nil, err
+	}
+	x, err := p.Resl(env, &exp.Call{Sig: sig, Spec: sp, Args: args, Env: env}, typ.Void)
+	if err != nil {
+		return nil, err
+	}
+	val, err := p.Eval(env, x)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		val = lit.Null{}
+	}
+	return val.MarshalJSON()
+}